@@ -2,82 +2,149 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/config"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/handler"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/service"
-	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/health"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/httpclient"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/server"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
-	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
 func main() {
-	cleanup := initTracer()
-	defer cleanup()
-
-	serviceENV := utils.GetEnvOrDefault("SERVICE_URL", "localhost")
-	serviceURL := fmt.Sprintf("http://%s:8080", serviceENV)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
 
-	temperatureRepository := repository.NewTemperatureRepository(serviceURL + "/?cep=%s")
+		slog.Error("service stopped with an error", "error", err)
+		os.Exit(1)
+	}
+}
 
-	inputService := service.NewInputService(temperatureRepository)
+func run(args []string) error {
+	cfg, err := config.LoadInputServer(args)
+	if err != nil {
+		return err
+	}
 
-	inputHandler := handler.NewInputHandler(inputService)
+	logger := logging.New(os.Stdout, logging.ParseLevel(cfg.Telemetry.LogLevel), logging.NewOTelHandler(cfg.Telemetry.ServiceName))
+	slog.SetDefault(logger)
+
+	shutdown, err := telemetry.Setup(telemetry.Settings{
+		ServiceName:     cfg.Telemetry.ServiceName,
+		OTLP:            cfg.Telemetry.OTLPEnabled,
+		CollectorURL:    cfg.Telemetry.CollectorURL,
+		MetricsInterval: cfg.Telemetry.MetricsInterval,
+		Prometheus:      cfg.Telemetry.Prometheus.Enabled,
+		PrometheusPort:  cfg.Telemetry.Prometheus.Port,
+	}, context.Background())
+	if err != nil {
+		return fmt.Errorf("error setting up telemetry: %w", err)
+	}
 
-	router := chi.NewRouter()
-	router.Use(middleware.Logger)
+	// Flushes the spans, metrics and logs of the drained requests before the
+	// process exits.
+	flushTelemetry := func(ctx context.Context) {
+		if err := shutdown(ctx); err != nil {
+			logger.Error("error shutting down telemetry", "error", err)
+		}
+	}
 
-	router.Post("/", inputHandler.GetTemperatureByCep)
+	httpClientSettings := httpclient.Settings{
+		Retry: httpclient.RetrySettings{
+			MaxRetries: cfg.HTTPClient.MaxRetries,
+			BaseDelay:  cfg.HTTPClient.RetryBaseDelay,
+			MaxDelay:   cfg.HTTPClient.RetryMaxDelay,
+		},
+		UserAgent: cfg.HTTPClient.UserAgent,
+		Contact:   cfg.HTTPClient.Contact,
+		Limiters:  httpclient.NewLimiters(cfg.RateLimits()),
+	}
 
-	log.Printf("server started on port 3000")
+	httpClient := httpclient.New(httpClientSettings)
 
-	err := http.ListenAndServe(":3000", router)
-	if err != nil {
-		log.Fatal("error starting server: ", err)
+	circuitBreakerSettings := circuitbreaker.Settings{
+		FailureThreshold:    cfg.CircuitBreaker.FailureThreshold,
+		OpenTimeout:         cfg.CircuitBreaker.OpenTimeout,
+		HalfOpenMaxRequests: cfg.CircuitBreaker.HalfOpenMaxRequests,
 	}
-}
-
-func initTracer() func() {
-	collectorENV := utils.GetEnvOrDefault("COLLECTOR_URL", "collector")
-	collectorURL := fmt.Sprintf("%s:4317", collectorENV)
 
-	ctx := context.Background()
+	serviceBCircuitBreaker := circuitbreaker.New("Service B", circuitBreakerSettings)
 
-	exporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(collectorURL),
+	temperatureRepository := repository.NewCircuitBreakerTemperatureRepository(
+		repository.NewTemperatureRepository(cfg.ServiceB.URL, httpClient, cfg.ServiceB.Timeout, logger),
+		serviceBCircuitBreaker,
 	)
-	if err != nil {
-		log.Fatalf("failed to create OTLP gRPC trace exporter: %v", err)
+	forecastRepository := repository.NewCircuitBreakerForecastRepository(
+		repository.NewForecastRepository(cfg.ServiceB.URL, httpClient, cfg.ServiceB.Timeout, logger),
+		serviceBCircuitBreaker,
+	)
+	batchRepository := repository.NewCircuitBreakerBatchRepository(
+		repository.NewBatchRepository(cfg.ServiceB.URL, httpClient, cfg.Batch.Timeout, logger),
+		serviceBCircuitBreaker,
+	)
+
+	inputService := service.NewInputService(temperatureRepository, forecastRepository, logger)
+
+	batchService := service.NewBatchService(batchRepository, logger)
+
+	inputHandler := handler.NewInputHandler(inputService, logger)
+	batchHandler := handler.NewBatchHandler(batchService, cfg.Batch.MaxSize, cfg.Batch.Timeout, logger)
+
+	// The probes identify themselves like every other call, but do not wait
+	// for the rate limits of the live traffic.
+	probeClient := httpclient.NewProbe(httpClientSettings)
+
+	healthChecks := map[string]func(context.Context) error{
+		"service-b": health.ServiceCheck(probeClient, strings.TrimSuffix(cfg.ServiceB.URL, "/")+"/healthz"),
 	}
 
-	resource := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String("Service A"),
-	)
+	if cfg.Telemetry.OTLPEnabled {
+		healthChecks["collector"] = health.TCPCheck(cfg.Telemetry.CollectorURL)
+	}
 
-	tracerProvider := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
-		trace.WithResource(resource),
-	)
+	healthCheck := health.New(cfg.Health.CacheTTL, cfg.Health.Timeout, health.NewDependencies(healthChecks, cfg.Health.Critical)...)
 
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	router := chi.NewRouter()
+	router.Use(logging.Middleware(logger))
+	router.Use(telemetry.RouteMiddleware)
 
-	return func() {
-		if err := tracerProvider.Shutdown(ctx); err != nil {
-			log.Fatalf("error closing trace provider: %v", err)
-		}
+	router.Get("/healthz", healthCheck.Liveness)
+	router.Get("/readyz", healthCheck.Readiness)
+	router.Post("/", inputHandler.GetTemperatureByCep)
+	router.Post("/forecast", inputHandler.GetForecastByCep)
+	router.Post("/batch", batchHandler.GetTemperatureByCeps)
+
+	httpServer := server.New(telemetry.NewHandler(router, cfg.Telemetry.ServiceName), server.Settings{
+		Port:         cfg.Server.Port,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gracefulShutdown := server.Shutdown{
+		DrainTimeout: cfg.Server.ShutdownTimeout,
+		FlushTimeout: cfg.Telemetry.FlushTimeout,
+		Flush:        flushTelemetry,
 	}
+
+	return server.Run(httpServer, gracefulShutdown, logger, ctx)
 }
@@ -2,82 +2,251 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/config"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/handler"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
-	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
-
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
-	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/health"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/httpclient"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/server"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 )
 
 func main() {
-	cleanup := initTracer()
-	defer cleanup()
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
 
-	addressRepository := repository.NewAddressRepository("https://viacep.com.br/ws/%s/json/")
-	coordinatesRepository := repository.NewCoordinatesRepository("https://nominatim.openstreetmap.org/search")
-	weatherByAddressRepository := repository.NewWeatherByAddressRepository("https://wttr.in/%s,%s,Brazil?format=j1")
-	weatherByCoordinatesRepository := repository.NewWeatherByCoordinatesRepository("https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&current_weather=true")
+		slog.Error("service stopped with an error", "error", err)
+		os.Exit(1)
+	}
+}
 
-	weatherService := service.NewWeatherService(addressRepository, coordinatesRepository, weatherByAddressRepository, weatherByCoordinatesRepository)
+func run(args []string) error {
+	cfg, err := config.LoadTemperatureServer(args)
+	if err != nil {
+		return err
+	}
 
-	weatherHandler := handler.NewWeatherHandler(weatherService)
+	logger := logging.New(os.Stdout, logging.ParseLevel(cfg.Telemetry.LogLevel), logging.NewOTelHandler(cfg.Telemetry.ServiceName))
+	slog.SetDefault(logger)
+
+	shutdown, err := telemetry.Setup(telemetry.Settings{
+		ServiceName:     cfg.Telemetry.ServiceName,
+		OTLP:            cfg.Telemetry.OTLPEnabled,
+		CollectorURL:    cfg.Telemetry.CollectorURL,
+		MetricsInterval: cfg.Telemetry.MetricsInterval,
+		Prometheus:      cfg.Telemetry.Prometheus.Enabled,
+		PrometheusPort:  cfg.Telemetry.Prometheus.Port,
+	}, context.Background())
+	if err != nil {
+		return fmt.Errorf("error setting up telemetry: %w", err)
+	}
 
-	router := chi.NewRouter()
-	router.Use(middleware.Logger)
+	// Flushes the spans, metrics and logs of the drained requests before the
+	// process exits.
+	flushTelemetry := func(ctx context.Context) {
+		if err := shutdown(ctx); err != nil {
+			logger.Error("error shutting down telemetry", "error", err)
+		}
+	}
 
-	router.Get("/", weatherHandler.GetWeatherByCEP)
+	httpClientSettings := httpclient.Settings{
+		Retry: httpclient.RetrySettings{
+			MaxRetries: cfg.HTTPClient.MaxRetries,
+			BaseDelay:  cfg.HTTPClient.RetryBaseDelay,
+			MaxDelay:   cfg.HTTPClient.RetryMaxDelay,
+		},
+		UserAgent: cfg.HTTPClient.UserAgent,
+		Contact:   cfg.HTTPClient.Contact,
+		Limiters:  httpclient.NewLimiters(cfg.RateLimits()),
+	}
 
-	log.Printf("server started on port 8080")
+	httpClient := httpclient.New(httpClientSettings)
+
+	circuitBreakerSettings := circuitbreaker.Settings{
+		FailureThreshold:    cfg.CircuitBreaker.FailureThreshold,
+		OpenTimeout:         cfg.CircuitBreaker.OpenTimeout,
+		HalfOpenMaxRequests: cfg.CircuitBreaker.HalfOpenMaxRequests,
+	}
 
-	err := http.ListenAndServe(":8080", router)
+	// The forecasts go to the same upstreams as the current weather, so they
+	// share their circuit breakers.
+	wttrInCircuitBreaker := circuitbreaker.New("wttr.in", circuitBreakerSettings)
+	openMeteoCircuitBreaker := circuitbreaker.New("open-meteo", circuitBreakerSettings)
+
+	// Each address provider has its own circuit breaker, so a dead provider is
+	// skipped at once.
+	addressProviders, err := repository.NewProviderAddressRepository(
+		cfg.AddressProviders,
+		cfg.AddressStrategy,
+		logger,
+		repository.NewAddressProvider("viacep", repository.NewCircuitBreakerAddressRepository(
+			repository.NewAddressRepository(cfg.ViaCEP.URL, httpClient, cfg.ViaCEP.Timeout, logger),
+			circuitbreaker.New("ViaCEP", circuitBreakerSettings),
+		)),
+		repository.NewAddressProvider("brasilapi", repository.NewCircuitBreakerAddressRepository(
+			repository.NewBrasilAPIAddressRepository(cfg.BrasilAPI.URL, httpClient, cfg.BrasilAPI.Timeout, logger),
+			circuitbreaker.New("BrasilAPI", circuitBreakerSettings),
+		)),
+		repository.NewAddressProvider("opencep", repository.NewCircuitBreakerAddressRepository(
+			repository.NewOpenCEPAddressRepository(cfg.OpenCEP.URL, httpClient, cfg.OpenCEP.Timeout, logger),
+			circuitbreaker.New("OpenCEP", circuitBreakerSettings),
+		)),
+		repository.NewAddressProvider("awesomeapi", repository.NewCircuitBreakerAddressRepository(
+			repository.NewAwesomeAPIAddressRepository(cfg.AwesomeAPI.URL, httpClient, cfg.AwesomeAPI.Timeout, logger),
+			circuitbreaker.New("AwesomeAPI", circuitBreakerSettings),
+		)),
+	)
 	if err != nil {
-		log.Fatal("error starting server: ", err)
+		return fmt.Errorf("error configuring address providers: %w", err)
 	}
-}
 
-func initTracer() func() {
-	collectorENV := utils.GetEnvOrDefault("COLLECTOR_URL", "collector")
-	collectorURL := fmt.Sprintf("%s:4317", collectorENV)
+	addressRepository := repository.NewCachedAddressRepository(
+		addressProviders,
+		cache.New[string, model.Address](cfg.Cache.Size, cfg.Cache.AddressTTL),
+	)
+	coordinatesRepository := repository.NewCachedCoordinatesRepository(
+		repository.NewCircuitBreakerCoordinatesRepository(
+			repository.NewCoordinatesRepository(cfg.Nominatim.URL, httpClient, cfg.Nominatim.Timeout, logger),
+			circuitbreaker.New("Nominatim", circuitBreakerSettings),
+		),
+		cache.New[string, model.Coordinates](cfg.Cache.Size, cfg.Cache.CoordinatesTTL),
+	)
+	weatherByAddressRepository := repository.NewCachedWeatherByAddressRepository(
+		repository.NewCircuitBreakerWeatherByAddressRepository(
+			repository.NewWeatherByAddressRepository(cfg.WttrIn.URL, httpClient, cfg.WttrIn.Timeout, logger),
+			wttrInCircuitBreaker,
+		),
+		cache.New[string, model.Weather](cfg.Cache.Size, cfg.Cache.WeatherTTL),
+	)
+	weatherByCoordinatesRepository := repository.NewCachedWeatherByCoordinatesRepository(
+		repository.NewCoalescedWeatherByCoordinatesRepository(
+			repository.NewCircuitBreakerWeatherByCoordinatesRepository(
+				repository.NewWeatherByCoordinatesRepository(cfg.OpenMeteo.URL, httpClient, cfg.OpenMeteo.Timeout, logger),
+				openMeteoCircuitBreaker,
+			),
+		),
+		cache.New[string, model.Weather](cfg.Cache.Size, cfg.Cache.WeatherTTL),
+	)
+	forecastByAddressRepository := repository.NewCachedForecastByAddressRepository(
+		repository.NewCircuitBreakerForecastByAddressRepository(
+			repository.NewForecastByAddressRepository(cfg.WttrIn.URL, httpClient, cfg.WttrIn.Timeout, logger),
+			wttrInCircuitBreaker,
+		),
+		cache.New[string, []model.ForecastPoint](cfg.Cache.Size, cfg.Cache.ForecastTTL),
+	)
+	forecastByCoordinatesRepository := repository.NewCachedForecastByCoordinatesRepository(
+		repository.NewCircuitBreakerForecastByCoordinatesRepository(
+			repository.NewForecastByCoordinatesRepository(cfg.OpenMeteo.URL, httpClient, cfg.OpenMeteo.Timeout, logger),
+			openMeteoCircuitBreaker,
+		),
+		cache.New[string, []model.ForecastPoint](cfg.Cache.Size, cfg.Cache.ForecastTTL),
+	)
 
-	ctx := context.Background()
+	historyRepository := repository.NewCachedHistoryRepository(
+		repository.NewCircuitBreakerHistoryRepository(
+			repository.NewHistoryRepository(cfg.OpenMeteoArchive.URL, httpClient, cfg.OpenMeteoArchive.Timeout, logger),
+			circuitbreaker.New("open-meteo archive", circuitBreakerSettings),
+		),
+		cache.New[string, []model.HistoryDay](cfg.Cache.Size, cfg.Cache.HistoryTTL),
+	)
 
-	exporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(collectorURL),
+	weatherProviders, err := service.NewWeatherProviderChain(
+		cfg.WeatherProviders,
+		service.NewWeatherByCoordinatesProvider("open-meteo", weatherByCoordinatesRepository),
+		service.NewWeatherByAddressProvider("wttr.in", weatherByAddressRepository),
 	)
 	if err != nil {
-		log.Fatalf("failed to create OTLP gRPC trace exporter: %v", err)
+		return fmt.Errorf("error configuring weather providers: %w", err)
 	}
 
-	resource := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String("Service B"),
+	forecastProviders, err := service.NewForecastProviderChain(
+		cfg.WeatherProviders,
+		service.NewForecastByCoordinatesProvider("open-meteo", forecastByCoordinatesRepository),
+		service.NewForecastByAddressProvider("wttr.in", forecastByAddressRepository),
 	)
+	if err != nil {
+		return fmt.Errorf("error configuring forecast providers: %w", err)
+	}
+
+	weatherService := service.NewWeatherService(addressRepository, coordinatesRepository, weatherProviders, forecastProviders, logger)
+
+	historyService := service.NewHistoryService(addressRepository, coordinatesRepository, historyRepository, logger)
+	batchService := service.NewBatchService(weatherService, cfg.Batch.Concurrency, logger)
+
+	weatherHandler := handler.NewWeatherHandler(weatherService, logger)
+	historyHandler := handler.NewHistoryHandler(historyService, logger)
+	batchHandler := handler.NewBatchHandler(batchService, cfg.Batch.MaxSize, cfg.Batch.Timeout, logger)
+
+	// The probes identify themselves like every other call, but do not wait
+	// for the rate limits of the live traffic.
+	probeClient := httpclient.NewProbe(httpClientSettings)
 
-	tracerProvider := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
-		trace.WithResource(resource),
+	healthChecks := map[string]func(context.Context) error{
+		"viacep":             health.HTTPCheck(probeClient, cfg.ViaCEP.URL),
+		"brasilapi":          health.HTTPCheck(probeClient, cfg.BrasilAPI.URL),
+		"opencep":            health.HTTPCheck(probeClient, cfg.OpenCEP.URL),
+		"awesomeapi":         health.HTTPCheck(probeClient, cfg.AwesomeAPI.URL),
+		"nominatim":          health.HTTPCheck(probeClient, cfg.Nominatim.URL),
+		"open-meteo":         health.HTTPCheck(probeClient, cfg.OpenMeteo.URL),
+		"open-meteo-archive": health.HTTPCheck(probeClient, cfg.OpenMeteoArchive.URL),
+		"wttr.in":            health.HTTPCheck(probeClient, cfg.WttrIn.URL),
+	}
+
+	if cfg.Telemetry.OTLPEnabled {
+		healthChecks["collector"] = health.TCPCheck(cfg.Telemetry.CollectorURL)
+	}
+
+	// Service B answers as long as one of the address providers it asks does.
+	healthDependencies := append(
+		health.NewDependencies(healthChecks, cfg.Health.Critical),
+		health.NewGroup("address-providers", cfg.AddressProviders, cfg.Health.Critical),
 	)
 
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	healthCheck := health.New(cfg.Health.CacheTTL, cfg.Health.Timeout, healthDependencies...)
 
-	return func() {
-		if err := tracerProvider.Shutdown(ctx); err != nil {
-			log.Fatalf("error closing trace provider: %v", err)
-		}
+	router := chi.NewRouter()
+	router.Use(logging.Middleware(logger))
+	router.Use(telemetry.RouteMiddleware)
+
+	router.Get("/healthz", healthCheck.Liveness)
+	router.Get("/readyz", healthCheck.Readiness)
+	router.Get("/", weatherHandler.GetWeatherByCEP)
+	router.Get("/forecast", weatherHandler.GetForecastByCEP)
+	router.Get("/history", historyHandler.GetHistoryByCEP)
+	router.Post("/batch", batchHandler.GetWeatherByCEPs)
+
+	httpServer := server.New(telemetry.NewHandler(router, cfg.Telemetry.ServiceName), server.Settings{
+		Port:         cfg.Server.Port,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gracefulShutdown := server.Shutdown{
+		DrainTimeout: cfg.Server.ShutdownTimeout,
+		FlushTimeout: cfg.Telemetry.FlushTimeout,
+		Flush:        flushTelemetry,
 	}
+
+	return server.Run(httpServer, gracefulShutdown, logger, ctx)
 }
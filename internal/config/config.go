@@ -0,0 +1,478 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"slices"
+	"time"
+)
+
+type Server struct {
+	Port            int           `yaml:"port" env:"SERVER_PORT"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+type Telemetry struct {
+	ServiceName     string        `yaml:"service_name" env:"SERVICE_NAME"`
+	OTLPEnabled     bool          `yaml:"otlp_enabled" env:"OTLP_ENABLED"`
+	CollectorURL    string        `yaml:"collector_endpoint" env:"COLLECTOR_ENDPOINT"`
+	MetricsInterval time.Duration `yaml:"metrics_interval" env:"METRICS_EXPORT_INTERVAL"`
+	Prometheus      Prometheus    `yaml:"prometheus"`
+	LogLevel        string        `yaml:"log_level" env:"LOG_LEVEL"`
+	FlushTimeout    time.Duration `yaml:"flush_timeout" env:"TELEMETRY_FLUSH_TIMEOUT"`
+}
+
+type Prometheus struct {
+	Enabled bool `yaml:"enabled" env:"PROMETHEUS_ENABLED"`
+	Port    int  `yaml:"port" env:"PROMETHEUS_PORT"`
+}
+
+type HTTPClient struct {
+	MaxRetries     int           `yaml:"max_retries" env:"HTTP_MAX_RETRIES"`
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay" env:"HTTP_RETRY_BASE_DELAY"`
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay" env:"HTTP_RETRY_MAX_DELAY"`
+	UserAgent      string        `yaml:"user_agent" env:"HTTP_USER_AGENT"`
+	Contact        string        `yaml:"contact" env:"HTTP_CONTACT"`
+}
+
+type CircuitBreaker struct {
+	FailureThreshold    int           `yaml:"failure_threshold" env:"CIRCUIT_BREAKER_FAILURE_THRESHOLD"`
+	OpenTimeout         time.Duration `yaml:"open_timeout" env:"CIRCUIT_BREAKER_OPEN_TIMEOUT"`
+	HalfOpenMaxRequests int           `yaml:"half_open_requests" env:"CIRCUIT_BREAKER_HALF_OPEN_REQUESTS"`
+}
+
+// Upstream is shared by every upstream API, its variables being prefixed by
+// the envPrefix tag of the field holding it, as in VIACEP_URL. RateLimit is
+// in requests per second, zero leaving the upstream unlimited.
+type Upstream struct {
+	URL       string        `yaml:"url" env:"URL"`
+	Timeout   time.Duration `yaml:"timeout" env:"TIMEOUT"`
+	RateLimit float64       `yaml:"rate_limit" env:"RATE_LIMIT"`
+}
+
+type Cache struct {
+	Size           int           `yaml:"size" env:"CACHE_SIZE"`
+	AddressTTL     time.Duration `yaml:"address_ttl" env:"CACHE_ADDRESS_TTL"`
+	CoordinatesTTL time.Duration `yaml:"coordinates_ttl" env:"CACHE_COORDINATES_TTL"`
+	WeatherTTL     time.Duration `yaml:"weather_ttl" env:"CACHE_WEATHER_TTL"`
+	ForecastTTL    time.Duration `yaml:"forecast_ttl" env:"CACHE_FORECAST_TTL"`
+	HistoryTTL     time.Duration `yaml:"history_ttl" env:"CACHE_HISTORY_TTL"`
+}
+
+// Health configures the readiness probe. Critical lists the dependencies
+// that make the service not ready when they are down.
+type Health struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT"`
+	Critical []string      `yaml:"critical" env:"HEALTH_CRITICAL"`
+}
+
+// Batch bounds the batch endpoint: MaxSize is the number of zipcodes a
+// request may carry, Concurrency how many of them Service B looks up at once
+// and Timeout how long the whole batch may take before the zipcodes still
+// pending are answered as timed out. Service A forwards whole batches, so it
+// does not use Concurrency.
+type Batch struct {
+	MaxSize     int           `yaml:"max_size" env:"BATCH_MAX_SIZE"`
+	Concurrency int           `yaml:"concurrency" env:"BATCH_CONCURRENCY"`
+	Timeout     time.Duration `yaml:"timeout" env:"BATCH_TIMEOUT"`
+}
+
+type InputServer struct {
+	Server         Server         `yaml:"server"`
+	Telemetry      Telemetry      `yaml:"telemetry"`
+	HTTPClient     HTTPClient     `yaml:"http_client"`
+	CircuitBreaker CircuitBreaker `yaml:"circuit_breaker"`
+	Health         Health         `yaml:"health"`
+	Batch          Batch          `yaml:"batch"`
+	ServiceB       Upstream       `yaml:"service_b" envPrefix:"SERVICE_B_"`
+}
+
+type TemperatureServer struct {
+	Server           Server         `yaml:"server"`
+	Telemetry        Telemetry      `yaml:"telemetry"`
+	HTTPClient       HTTPClient     `yaml:"http_client"`
+	CircuitBreaker   CircuitBreaker `yaml:"circuit_breaker"`
+	Cache            Cache          `yaml:"cache"`
+	Health           Health         `yaml:"health"`
+	Batch            Batch          `yaml:"batch"`
+	ViaCEP           Upstream       `yaml:"viacep" envPrefix:"VIACEP_"`
+	BrasilAPI        Upstream       `yaml:"brasilapi" envPrefix:"BRASILAPI_"`
+	OpenCEP          Upstream       `yaml:"opencep" envPrefix:"OPENCEP_"`
+	AwesomeAPI       Upstream       `yaml:"awesomeapi" envPrefix:"AWESOMEAPI_"`
+	Nominatim        Upstream       `yaml:"nominatim" envPrefix:"NOMINATIM_"`
+	WttrIn           Upstream       `yaml:"wttr_in" envPrefix:"WTTR_IN_"`
+	OpenMeteo        Upstream       `yaml:"open_meteo" envPrefix:"OPEN_METEO_"`
+	OpenMeteoArchive Upstream       `yaml:"open_meteo_archive" envPrefix:"OPEN_METEO_ARCHIVE_"`
+	WeatherProviders []string       `yaml:"weather_providers" env:"WEATHER_PROVIDERS"`
+	AddressProviders []string       `yaml:"address_providers" env:"ADDRESS_PROVIDERS"`
+	AddressStrategy  string         `yaml:"address_strategy" env:"ADDRESS_STRATEGY"`
+}
+
+func LoadInputServer(args []string) (*InputServer, error) {
+	cfg := &InputServer{
+		Server:         defaultServer(3000),
+		Telemetry:      defaultTelemetry("Service A", 9464),
+		HTTPClient:     defaultHTTPClient(0),
+		CircuitBreaker: defaultCircuitBreaker(),
+		Health:         defaultHealth("service-b"),
+		Batch:          defaultBatch(25 * time.Second),
+		ServiceB:       Upstream{URL: "http://localhost:8080", Timeout: 20 * time.Second},
+	}
+
+	if err := Load(cfg, args); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func LoadTemperatureServer(args []string) (*TemperatureServer, error) {
+	cfg := &TemperatureServer{
+		Server:         defaultServer(8080),
+		Telemetry:      defaultTelemetry("Service B", 9465),
+		HTTPClient:     defaultHTTPClient(2),
+		CircuitBreaker: defaultCircuitBreaker(),
+		Cache: Cache{
+			Size:           1000,
+			AddressTTL:     24 * time.Hour,
+			CoordinatesTTL: 7 * 24 * time.Hour,
+			WeatherTTL:     10 * time.Minute,
+			ForecastTTL:    time.Hour,
+			HistoryTTL:     30 * 24 * time.Hour,
+		},
+		Health:           defaultHealth("address-providers"),
+		Batch:            defaultBatch(20 * time.Second),
+		ViaCEP:           Upstream{URL: "https://viacep.com.br", Timeout: 5 * time.Second, RateLimit: 10},
+		BrasilAPI:        Upstream{URL: "https://brasilapi.com.br", Timeout: 5 * time.Second, RateLimit: 5},
+		OpenCEP:          Upstream{URL: "https://opencep.com", Timeout: 5 * time.Second, RateLimit: 5},
+		AwesomeAPI:       Upstream{URL: "https://cep.awesomeapi.com.br", Timeout: 5 * time.Second, RateLimit: 5},
+		Nominatim:        Upstream{URL: "https://nominatim.openstreetmap.org", Timeout: 5 * time.Second, RateLimit: 1},
+		WttrIn:           Upstream{URL: "https://wttr.in", Timeout: 5 * time.Second, RateLimit: 5},
+		OpenMeteo:        Upstream{URL: "https://api.open-meteo.com", Timeout: 5 * time.Second, RateLimit: 10},
+		OpenMeteoArchive: Upstream{URL: "https://archive-api.open-meteo.com", Timeout: 10 * time.Second, RateLimit: 5},
+		WeatherProviders: []string{"open-meteo", "wttr.in"},
+		AddressProviders: []string{"viacep", "brasilapi", "opencep", "awesomeapi"},
+		AddressStrategy:  "fallback",
+	}
+
+	if err := Load(cfg, args); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func defaultServer(port int) Server {
+	return Server{
+		Port:            port,
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    30 * time.Second,
+		IdleTimeout:     2 * time.Minute,
+		ShutdownTimeout: 15 * time.Second,
+	}
+}
+
+func defaultTelemetry(serviceName string, prometheusPort int) Telemetry {
+	return Telemetry{
+		ServiceName:     serviceName,
+		OTLPEnabled:     true,
+		CollectorURL:    "collector:4317",
+		MetricsInterval: 15 * time.Second,
+		Prometheus:      Prometheus{Port: prometheusPort},
+		LogLevel:        "info",
+		FlushTimeout:    5 * time.Second,
+	}
+}
+
+// Service B already retries its upstreams and answers 503 at once while its
+// breakers are open, so Service A does not retry it by default.
+func defaultHTTPClient(maxRetries int) HTTPClient {
+	return HTTPClient{
+		MaxRetries:     maxRetries,
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  2 * time.Second,
+		UserAgent:      "go-telemetry-cep-temperature (+https://github.com/aronkst/go-telemetry-cep-temperature)",
+	}
+}
+
+func defaultCircuitBreaker() CircuitBreaker {
+	return CircuitBreaker{
+		FailureThreshold:    5,
+		OpenTimeout:         30 * time.Second,
+		HalfOpenMaxRequests: 1,
+	}
+}
+
+func defaultHealth(critical ...string) Health {
+	return Health{
+		CacheTTL: 10 * time.Second,
+		Timeout:  2 * time.Second,
+		Critical: critical,
+	}
+}
+
+func defaultBatch(timeout time.Duration) Batch {
+	return Batch{
+		MaxSize:     500,
+		Concurrency: 10,
+		Timeout:     timeout,
+	}
+}
+
+func (c *InputServer) Validate() error {
+	errs := []error{
+		c.Server.validate(),
+		c.Telemetry.validate(c.Server.Port),
+		c.HTTPClient.validate(),
+		c.CircuitBreaker.validate(),
+		c.Health.validate("service-b", "collector"),
+		c.Batch.validate(c.Server.WriteTimeout),
+		c.ServiceB.validate("service_b"),
+	}
+
+	// The answer of Service B must fit in the response to the client.
+	if c.Server.WriteTimeout > 0 && c.Server.WriteTimeout <= c.ServiceB.Timeout {
+		errs = append(errs, fmt.Errorf("server.write_timeout (%s) must be greater than service_b.timeout (%s)", c.Server.WriteTimeout, c.ServiceB.Timeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *TemperatureServer) Validate() error {
+	errs := []error{
+		c.Server.validate(),
+		c.Telemetry.validate(c.Server.Port),
+		c.HTTPClient.validate(),
+		c.CircuitBreaker.validate(),
+		c.Cache.validate(),
+		c.Health.validate("address-providers", "viacep", "brasilapi", "opencep", "awesomeapi", "nominatim", "open-meteo", "open-meteo-archive", "wttr.in", "collector"),
+		c.Batch.validate(c.Server.WriteTimeout),
+		c.ViaCEP.validate("viacep"),
+		c.BrasilAPI.validate("brasilapi"),
+		c.OpenCEP.validate("opencep"),
+		c.AwesomeAPI.validate("awesomeapi"),
+		c.Nominatim.validate("nominatim"),
+		c.WttrIn.validate("wttr_in"),
+		c.OpenMeteo.validate("open_meteo"),
+		c.OpenMeteoArchive.validate("open_meteo_archive"),
+	}
+
+	if len(c.WeatherProviders) == 0 {
+		errs = append(errs, errors.New("weather_providers must list at least one provider"))
+	}
+
+	if len(c.AddressProviders) == 0 {
+		errs = append(errs, errors.New("address_providers must list at least one provider"))
+	}
+
+	if c.AddressStrategy != "fallback" && c.AddressStrategy != "race" {
+		errs = append(errs, fmt.Errorf("address_strategy must be fallback or race, got %q", c.AddressStrategy))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RateLimits maps the host of Service B to its rate limit.
+func (c *InputServer) RateLimits() map[string]float64 {
+	return rateLimits(c.ServiceB)
+}
+
+// RateLimits maps the host of each upstream to its rate limit. Upstreams
+// sharing a host share the lowest of their limits.
+func (c *TemperatureServer) RateLimits() map[string]float64 {
+	return rateLimits(c.ViaCEP, c.BrasilAPI, c.OpenCEP, c.AwesomeAPI, c.Nominatim, c.WttrIn, c.OpenMeteo, c.OpenMeteoArchive)
+}
+
+func rateLimits(upstreams ...Upstream) map[string]float64 {
+	limits := make(map[string]float64)
+
+	for _, upstream := range upstreams {
+		parsed, err := url.Parse(upstream.URL)
+		if err != nil || upstream.RateLimit <= 0 {
+			continue
+		}
+
+		if limit, ok := limits[parsed.Host]; !ok || upstream.RateLimit < limit {
+			limits[parsed.Host] = upstream.RateLimit
+		}
+	}
+
+	return limits
+}
+
+func (s Server) validate() error {
+	errs := []error{validatePort("server.port", s.Port)}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_timeout", s.ReadTimeout},
+		{"server.write_timeout", s.WriteTimeout},
+		{"server.idle_timeout", s.IdleTimeout},
+		{"server.shutdown_timeout", s.ShutdownTimeout},
+	}
+
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", timeout.name, timeout.value))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t Telemetry) validate(serverPort int) error {
+	var errs []error
+
+	if t.ServiceName == "" {
+		errs = append(errs, errors.New("telemetry.service_name must not be empty"))
+	}
+
+	if t.FlushTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("telemetry.flush_timeout must be positive, got %s", t.FlushTimeout))
+	}
+
+	if t.OTLPEnabled {
+		if _, _, err := net.SplitHostPort(t.CollectorURL); err != nil {
+			errs = append(errs, fmt.Errorf("telemetry.collector_endpoint must be a host:port, got %q", t.CollectorURL))
+		}
+
+		if t.MetricsInterval <= 0 {
+			errs = append(errs, fmt.Errorf("telemetry.metrics_interval must be positive, got %s", t.MetricsInterval))
+		}
+	}
+
+	if t.Prometheus.Enabled {
+		if err := validatePort("telemetry.prometheus.port", t.Prometheus.Port); err != nil {
+			errs = append(errs, err)
+		} else if t.Prometheus.Port == serverPort {
+			errs = append(errs, fmt.Errorf("telemetry.prometheus.port must differ from server.port %d", serverPort))
+		}
+	}
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, t.LogLevel) {
+		errs = append(errs, fmt.Errorf("telemetry.log_level must be debug, info, warn or error, got %q", t.LogLevel))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h HTTPClient) validate() error {
+	var errs []error
+
+	if h.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("http_client.max_retries must not be negative, got %d", h.MaxRetries))
+	}
+
+	if h.RetryBaseDelay < 0 || h.RetryMaxDelay < h.RetryBaseDelay {
+		errs = append(errs, fmt.Errorf("http_client.retry_base_delay (%s) must not be negative nor greater than retry_max_delay (%s)", h.RetryBaseDelay, h.RetryMaxDelay))
+	}
+
+	if h.UserAgent == "" {
+		errs = append(errs, errors.New("http_client.user_agent must identify the service, as asked by the upstream usage policies"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c CircuitBreaker) validate() error {
+	var errs []error
+
+	if c.FailureThreshold < 1 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.failure_threshold must be at least 1, got %d", c.FailureThreshold))
+	}
+
+	if c.OpenTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.open_timeout must be positive, got %s", c.OpenTimeout))
+	}
+
+	if c.HalfOpenMaxRequests < 1 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.half_open_requests must be at least 1, got %d", c.HalfOpenMaxRequests))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c Cache) validate() error {
+	if c.Size < 0 || c.AddressTTL < 0 || c.CoordinatesTTL < 0 || c.WeatherTTL < 0 || c.ForecastTTL < 0 || c.HistoryTTL < 0 {
+		return errors.New("cache size and ttls must not be negative, zero disables the cache")
+	}
+
+	return nil
+}
+
+func (h Health) validate(dependencies ...string) error {
+	var errs []error
+
+	if h.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("health.cache_ttl must not be negative, got %s", h.CacheTTL))
+	}
+
+	if h.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("health.timeout must be positive, got %s", h.Timeout))
+	}
+
+	for _, name := range h.Critical {
+		if !slices.Contains(dependencies, name) {
+			errs = append(errs, fmt.Errorf("health.critical has unknown dependency %q, expected one of %v", name, dependencies))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate requires the batch to end before the write timeout, so its
+// partial answer is still written.
+func (b Batch) validate(writeTimeout time.Duration) error {
+	var errs []error
+
+	if b.MaxSize < 1 {
+		errs = append(errs, fmt.Errorf("batch.max_size must be at least 1, got %d", b.MaxSize))
+	}
+
+	if b.Concurrency < 1 {
+		errs = append(errs, fmt.Errorf("batch.concurrency must be at least 1, got %d", b.Concurrency))
+	}
+
+	if b.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("batch.timeout must be positive, got %s", b.Timeout))
+	} else if writeTimeout > 0 && b.Timeout >= writeTimeout {
+		errs = append(errs, fmt.Errorf("batch.timeout (%s) must be shorter than server.write_timeout (%s)", b.Timeout, writeTimeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (u Upstream) validate(name string) error {
+	var errs []error
+
+	if parsed, err := url.Parse(u.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errs = append(errs, fmt.Errorf("%s.url must be an absolute http or https url, got %q", name, u.URL))
+	}
+
+	if u.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s.timeout must be positive, got %s", name, u.Timeout))
+	}
+
+	if u.RateLimit < 0 || math.IsNaN(u.RateLimit) || math.IsInf(u.RateLimit, 0) {
+		errs = append(errs, fmt.Errorf("%s.rate_limit must be a non-negative number, got %v", name, u.RateLimit))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validatePort(name string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be between 1 and 65535, got %d", name, port)
+	}
+
+	return nil
+}
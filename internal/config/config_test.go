@@ -0,0 +1,278 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/config"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return path
+}
+
+func TestLoadTemperatureServerDefaults(t *testing.T) {
+	cfg, err := config.LoadTemperatureServer(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != 8080 || cfg.Telemetry.ServiceName != "Service B" || cfg.Telemetry.CollectorURL != "collector:4317" {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+
+	if cfg.ViaCEP.URL != "https://viacep.com.br" || cfg.ViaCEP.Timeout != 5*time.Second {
+		t.Errorf("Unexpected ViaCEP defaults %+v", cfg.ViaCEP)
+	}
+
+	if !reflect.DeepEqual(cfg.WeatherProviders, []string{"open-meteo", "wttr.in"}) {
+		t.Errorf("Unexpected weather providers %v", cfg.WeatherProviders)
+	}
+
+	if !reflect.DeepEqual(cfg.AddressProviders, []string{"viacep", "brasilapi", "opencep", "awesomeapi"}) || cfg.AddressStrategy != "fallback" {
+		t.Errorf("Unexpected address providers %v and strategy %s", cfg.AddressProviders, cfg.AddressStrategy)
+	}
+
+	// A single address provider going down leaves the others to answer.
+	if !reflect.DeepEqual(cfg.Health.Critical, []string{"address-providers"}) {
+		t.Errorf("Unexpected critical dependencies %v", cfg.Health.Critical)
+	}
+}
+
+func TestLoadInputServerDefaults(t *testing.T) {
+	cfg, err := config.LoadInputServer(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != 3000 || cfg.Telemetry.ServiceName != "Service A" || cfg.ServiceB.URL != "http://localhost:8080" {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+
+	if cfg.HTTPClient.MaxRetries != 0 {
+		t.Errorf("Expected Service B not to be retried, got %d retries", cfg.HTTPClient.MaxRetries)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+server:
+  port: 9000
+viacep:
+  url: http://file.local
+  timeout: 1s
+cache:
+  weather_ttl: 1m
+weather_providers: [wttr.in]
+`)
+
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("VIACEP_URL", "http://env.local")
+	t.Setenv("SERVER_PORT", "9001")
+	t.Setenv("WEATHER_PROVIDERS", "wttr.in, open-meteo")
+
+	cfg, err := config.LoadTemperatureServer([]string{"-server-port", "9002"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != 9002 {
+		t.Errorf("Expected the flag to win, got port %d", cfg.Server.Port)
+	}
+
+	if cfg.ViaCEP.URL != "http://env.local" {
+		t.Errorf("Expected the env to override the file, got %s", cfg.ViaCEP.URL)
+	}
+
+	if cfg.ViaCEP.Timeout != time.Second || cfg.Cache.WeatherTTL != time.Minute {
+		t.Errorf("Expected the file to override the defaults, got %s and %s", cfg.ViaCEP.Timeout, cfg.Cache.WeatherTTL)
+	}
+
+	if cfg.Cache.AddressTTL != 24*time.Hour {
+		t.Errorf("Expected the defaults to be kept, got %s", cfg.Cache.AddressTTL)
+	}
+
+	if !reflect.DeepEqual(cfg.WeatherProviders, []string{"wttr.in", "open-meteo"}) {
+		t.Errorf("Unexpected weather providers %v", cfg.WeatherProviders)
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	t.Setenv("NOMINATIM_RATE_LIMIT", "0.5")
+	t.Setenv("WTTR_IN_RATE_LIMIT", "0")
+	t.Setenv("OPEN_METEO_ARCHIVE_URL", "https://api.open-meteo.com")
+
+	cfg, err := config.LoadTemperatureServer(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]float64{
+		"viacep.com.br":               10,
+		"brasilapi.com.br":            5,
+		"opencep.com":                 5,
+		"cep.awesomeapi.com.br":       5,
+		"nominatim.openstreetmap.org": 0.5,
+		"api.open-meteo.com":          5,
+	}
+
+	if limits := cfg.RateLimits(); !reflect.DeepEqual(limits, expected) {
+		t.Errorf("Expected %v, got %v", expected, limits)
+	}
+}
+
+func TestLoadTemperatureServerInvalidAddressProviders(t *testing.T) {
+	t.Setenv("ADDRESS_PROVIDERS", "")
+	t.Setenv("ADDRESS_STRATEGY", "fastest")
+
+	_, err := config.LoadTemperatureServer(nil)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	for _, expected := range []string{"address_providers must list at least one provider", `address_strategy must be fallback or race, got "fastest"`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %q", expected, err.Error())
+		}
+	}
+}
+
+func TestLoadConfigFlag(t *testing.T) {
+	path := writeFile(t, "service_b:\n  url: http://service-b:8080\n")
+
+	cfg, err := config.LoadInputServer([]string{"-config", path, "-service-b-timeout", "3s"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.ServiceB.URL != "http://service-b:8080" || cfg.ServiceB.Timeout != 3*time.Second {
+		t.Errorf("Unexpected Service B settings %+v", cfg.ServiceB)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "invalid env value",
+			env:      map[string]string{"HTTP_MAX_RETRIES": "many"},
+			expected: []string{`invalid value "many" for HTTP_MAX_RETRIES`},
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"-unknown"},
+			expected: []string{"flag provided but not defined: -unknown"},
+		},
+		{
+			name:     "renamed variable",
+			env:      map[string]string{"SERVICE_URL": "service-b"},
+			expected: []string{"SERVICE_URL is no longer read, it was renamed SERVICE_B_URL"},
+		},
+		{
+			name:     "missing file",
+			args:     []string{"-config", "/does/not/exist.yaml"},
+			expected: []string{"error when reading configuration file"},
+		},
+		{
+			name: "invalid values",
+			env: map[string]string{
+				"SERVER_PORT":        "0",
+				"SERVICE_B_URL":      "service-b",
+				"SERVICE_B_TIMEOUT":  "0s",
+				"COLLECTOR_ENDPOINT": "collector",
+				"LOG_LEVEL":          "verbose",
+				"HEALTH_CRITICAL":    "service-b,viacep",
+				"BATCH_CONCURRENCY":  "0",
+				"BATCH_TIMEOUT":      "1m",
+				"HTTP_USER_AGENT":    "",
+			},
+			expected: []string{
+				"invalid configuration",
+				"server.port must be between 1 and 65535, got 0",
+				`service_b.url must be an absolute http or https url, got "service-b"`,
+				"service_b.timeout must be positive, got 0s",
+				`telemetry.collector_endpoint must be a host:port, got "collector"`,
+				`telemetry.log_level must be debug, info, warn or error, got "verbose"`,
+				`health.critical has unknown dependency "viacep"`,
+				"batch.concurrency must be at least 1, got 0",
+				"batch.timeout (1m0s) must be shorter than server.write_timeout (30s)",
+				"http_client.user_agent must identify the service",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := config.LoadInputServer(test.args)
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+
+			for _, expected := range test.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected %q in %q", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"unknown key", "server:\n  prot: 9000\n", "field prot not found"},
+		{"invalid yaml", "server: [\n", "error when parsing configuration file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := config.LoadInputServer([]string{"-config", writeFile(t, test.content)})
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadEmptyFile(t *testing.T) {
+	cfg, err := config.LoadInputServer([]string{"-config", writeFile(t, "")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != 3000 {
+		t.Errorf("Expected the defaults, got %+v", cfg.Server)
+	}
+}
+
+func TestExampleFiles(t *testing.T) {
+	if _, err := config.LoadInputServer([]string{"-config", "../../configs/input_server.yaml"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if _, err := config.LoadTemperatureServer([]string{"-config", "../../configs/temperature_server.yaml"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// renamed maps the variables read before the typed configuration to the ones
+// that replaced them. They took bare hostnames, so they can not be aliases.
+var renamed = map[string]string{
+	"SERVICE_URL":   "SERVICE_B_URL, which takes a URL such as http://service-b:8080",
+	"COLLECTOR_URL": "COLLECTOR_ENDPOINT, which takes a host:port such as collector:4317",
+}
+
+// Validator is implemented by the configurations checked once loaded.
+type Validator interface {
+	Validate() error
+}
+
+// Load fills cfg, a pointer to a struct holding the defaults, from the
+// sources below, each one overriding the previous:
+//
+//   - the YAML file given by the -config flag or the CONFIG_FILE variable;
+//   - the environment variables named by the env tags of the fields, after
+//     the envPrefix tags of the structs holding them;
+//   - the flags named after the same variables, VIACEP_URL being -viacep-url.
+//
+// Unknown keys in the file and the variables that were renamed are errors, so
+// a typo or an outdated deployment does not silently fall back to a default.
+// The configuration is validated at the end when it implements Validator.
+func Load(cfg any, args []string) error {
+	for old, replacement := range renamed {
+		if _, ok := os.LookupEnv(old); ok {
+			return fmt.Errorf("%s is no longer read, it was renamed %s", old, replacement)
+		}
+	}
+
+	fields, err := envFields(reflect.ValueOf(cfg).Elem(), "")
+	if err != nil {
+		return err
+	}
+
+	flagSet := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := flagSet.String("config", os.Getenv("CONFIG_FILE"), "path of the YAML configuration file")
+
+	flagValues := map[string]string{}
+	for _, field := range fields {
+		name := flagName(field.env)
+		flagSet.Func(name, fmt.Sprintf("overrides %s", field.env), func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return fmt.Errorf("error when reading configuration file: %w", err)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("error when parsing configuration file %s: %w", *configFile, err)
+		}
+	}
+
+	for _, field := range fields {
+		if value, ok := os.LookupEnv(field.env); ok {
+			if err := setValue(field.value, value); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %w", value, field.env, err)
+			}
+		}
+	}
+
+	for _, field := range fields {
+		name := flagName(field.env)
+		if value, ok := flagValues[name]; ok {
+			if err := setValue(field.value, value); err != nil {
+				return fmt.Errorf("invalid value %q for -%s: %w", value, name, err)
+			}
+		}
+	}
+
+	if validator, ok := cfg.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type envField struct {
+	env   string
+	value reflect.Value
+}
+
+// envFields walks the nested structs of the configuration and returns every
+// field tagged with the name of its environment variable.
+func envFields(value reflect.Value, prefix string) ([]envField, error) {
+	if value.Kind() != reflect.Struct {
+		return nil, errors.New("configuration must be a pointer to a struct")
+	}
+
+	var fields []envField
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		if env := field.Tag.Get("env"); env != "" {
+			fields = append(fields, envField{env: prefix + env, value: value.Field(i)})
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			nested, err := envFields(value.Field(i), prefix+field.Tag.Get("envPrefix"))
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, nested...)
+		}
+	}
+
+	return fields, nil
+}
+
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(number))
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(number)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(boolean)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
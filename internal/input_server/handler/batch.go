@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/service"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+type BatchHandler struct {
+	batchService service.BatchService
+	maxSize      int
+	timeout      time.Duration
+	logger       *slog.Logger
+}
+
+func NewBatchHandler(batchService service.BatchService, maxSize int, timeout time.Duration, logger *slog.Logger) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+		maxSize:      maxSize,
+		timeout:      timeout,
+		logger:       logger,
+	}
+}
+
+// GetTemperatureByCeps reads a JSON array of zipcodes, validates it and
+// forwards it whole to the batch endpoint of Service B, answering 200 with the
+// items of Service B, one per zipcode. The call to Service B is bounded by the
+// batch timeout, after which every item is answered as timed out, before the
+// server gives up on the response.
+func (h *BatchHandler) GetTemperatureByCeps(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BatchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "BatchHandler.GetTemperatureByCeps")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	items, err := h.getTemperatures(r.Body, ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+func (h *BatchHandler) getTemperatures(body io.Reader, ctx context.Context) ([]temperatureServerModel.BatchItem, error) {
+	ceps, err := temperatureServerModel.DecodeBatch(body, h.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.batchService.GetTemperatureByCeps(ceps, ctx), nil
+}
@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/handler"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockBatchService struct{}
+
+func (m *MockBatchService) GetTemperatureByCeps(ceps []string, ctx context.Context) []temperatureServerModel.BatchItem {
+	items := make([]temperatureServerModel.BatchItem, len(ceps))
+	for i, cep := range ceps {
+		items[i] = temperatureServerModel.NewBatchItem(cep, &temperatureServerModel.Temperature{City: "Cidade", Celsius: 30, Fahrenheit: 86, Kelvin: 303.15}, nil)
+	}
+
+	return items
+}
+
+func TestGetTemperatureByCeps(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "valid batch",
+			body:           `["12345678","12345678"]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"cep":"12345678","status":200,"result":{"city":"Cidade","temp_C":30,"temp_F":86,"temp_K":303.15}},{"cep":"12345678","status":200,"result":{"city":"Cidade","temp_C":30,"temp_F":86,"temp_K":303.15}}]`,
+		},
+		{
+			name:           "too many",
+			body:           `["12345678","12345679","12345670"]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid batch request: at most 2 zipcodes are allowed, got 3",
+		},
+		{
+			name:           "invalid body",
+			body:           `"12345678"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid batch request: the body must be a JSON array of zipcodes",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := handler.NewBatchHandler(&MockBatchService{}, 2, time.Second, logging.Discard())
+
+			responseRecorder := httptest.NewRecorder()
+			handler.GetTemperatureByCeps(responseRecorder, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(test.body)))
+
+			if status := responseRecorder.Code; status != test.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedStatus)
+			}
+
+			if strings.Trim(responseRecorder.Body.String(), "\n") != test.expectedBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
@@ -3,70 +3,99 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/service"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type InputHandler struct {
 	inputService service.InputService
+	logger       *slog.Logger
 }
 
-func NewInputHandler(inputService service.InputService) *InputHandler {
+func NewInputHandler(inputService service.InputService, logger *slog.Logger) *InputHandler {
 	return &InputHandler{
 		inputService: inputService,
+		logger:       logger,
 	}
 }
 
 func (h *InputHandler) GetTemperatureByCep(w http.ResponseWriter, r *http.Request) {
 	tracer := otel.Tracer("InputHandler")
 
-	ctx := r.Context()
-	ctxDistributed := context.Background()
-
-	ctx, spanRoute := tracer.Start(ctx, "POST /")
-	defer spanRoute.End()
-
-	ctx, span := tracer.Start(ctx, "InputHandler.GetTemperatureByCep")
+	ctx, span := tracer.Start(r.Context(), "InputHandler.GetTemperatureByCep")
 	defer span.End()
 
-	ctxDistributed, spanDistributedStart := tracer.Start(ctxDistributed, "Distributed")
-	defer spanDistributedStart.End()
-
-	ctxDistributed, spanDistributedStartRoute := tracer.Start(ctxDistributed, "POST /")
-	defer spanDistributedStartRoute.End()
-
-	ctxDistributed, spanDistributed := tracer.Start(ctxDistributed, "InputHandler.GetTemperatureByCep")
-	defer spanDistributed.End()
-
 	var zipcode model.Zipcode
 
 	err := json.NewDecoder(r.Body).Decode(&zipcode)
 	if err != nil {
+		telemetry.RecordError(span, err)
+		h.logger.WarnContext(ctx, "invalid request body", "error", err)
 		http.Error(w, "invalid body", http.StatusInternalServerError)
 		return
 	}
 
-	temperature, err := h.inputService.GetTemperatureByCep(&zipcode, ctx, ctxDistributed)
+	span.SetAttributes(telemetry.CEPAttribute(zipcode.Cep))
+
+	temperature, err := h.inputService.GetTemperatureByCep(&zipcode, ctx)
 	if err != nil {
-		var errorStatusCode int
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
+		return
+	}
 
-		switch err.Error() {
-		case "invalid zipcode":
-			errorStatusCode = http.StatusUnprocessableEntity
-		case "can not find zipcode":
-			errorStatusCode = http.StatusNotFound
-		default:
-			errorStatusCode = http.StatusInternalServerError
-		}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(temperature)
+}
 
-		http.Error(w, err.Error(), errorStatusCode)
+// GetForecastByCep validates the forecast parameters before calling Service B,
+// defaulting them as Service B does.
+func (h *InputHandler) GetForecastByCep(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("InputHandler")
 
+	ctx, span := tracer.Start(r.Context(), "InputHandler.GetForecastByCep")
+	defer span.End()
+
+	forecast, err := h.getForecast(r.Body, ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(temperature)
+	json.NewEncoder(w).Encode(forecast)
+}
+
+func (h *InputHandler) getForecast(body io.Reader, ctx context.Context) (*temperatureServerModel.Forecast, error) {
+	request := model.ForecastRequest{
+		Days:        temperatureServerModel.DefaultForecastDays,
+		Granularity: temperatureServerModel.GranularityDaily,
+	}
+
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		return nil, fmt.Errorf("%w: the body must be a JSON object with the cep", apperrors.ErrInvalidForecast)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(telemetry.CEPAttribute(request.Cep))
+
+	query := temperatureServerModel.ForecastQuery{Days: request.Days, Granularity: request.Granularity}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	return h.inputService.GetForecastByCep(&request, ctx)
 }
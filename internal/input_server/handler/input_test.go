@@ -12,24 +12,33 @@ import (
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/handler"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
 	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 type MockInputService struct {
 	Temperature *temperatureServerModel.Temperature
+	Forecast    *temperatureServerModel.Forecast
+	Request     *model.ForecastRequest
 	Err         error
 }
 
-func (m *MockInputService) GetTemperatureByCep(*model.Zipcode, context.Context, context.Context) (*temperatureServerModel.Temperature, error) {
+func (m *MockInputService) GetTemperatureByCep(*model.Zipcode, context.Context) (*temperatureServerModel.Temperature, error) {
 	return m.Temperature, m.Err
 }
 
+func (m *MockInputService) GetForecastByCep(request *model.ForecastRequest, ctx context.Context) (*temperatureServerModel.Forecast, error) {
+	m.Request = request
+	return m.Forecast, m.Err
+}
+
 func TestGetTemperatureByCep_ValidCEP(t *testing.T) {
 	mockService := &MockInputService{
 		Temperature: &temperatureServerModel.Temperature{City: "Cidade", Celsius: 30, Fahrenheit: 86, Kelvin: 303.15},
 		Err:         nil,
 	}
 
-	handler := handler.NewInputHandler(mockService)
+	handler := handler.NewInputHandler(mockService, logging.Discard())
 
 	body := bytes.NewBufferString(`{"cep": "12345678"}`)
 	req, err := http.NewRequest("POST", "/", body)
@@ -56,7 +65,7 @@ func TestGetTemperatureByCep_InvalidBody(t *testing.T) {
 		Err:         nil,
 	}
 
-	handler := handler.NewInputHandler(mockService)
+	handler := handler.NewInputHandler(mockService, logging.Discard())
 
 	body := bytes.NewBufferString(`error`)
 	req, err := http.NewRequest("POST", "/", body)
@@ -80,10 +89,10 @@ func TestGetTemperatureByCep_InvalidBody(t *testing.T) {
 func TestGetTemperatureByCep_InvalidCEP(t *testing.T) {
 	mockService := &MockInputService{
 		Temperature: nil,
-		Err:         fmt.Errorf("invalid zipcode"),
+		Err:         fmt.Errorf("error when getting address: %w", apperrors.ErrInvalidCEP),
 	}
 
-	handler := handler.NewInputHandler(mockService)
+	handler := handler.NewInputHandler(mockService, logging.Discard())
 
 	body := bytes.NewBufferString(`{"cep": "12345-678"}`)
 	req, err := http.NewRequest("POST", "/", body)
@@ -107,10 +116,10 @@ func TestGetTemperatureByCep_InvalidCEP(t *testing.T) {
 func TestGetTemperatureByCep_NotFound(t *testing.T) {
 	mockService := &MockInputService{
 		Temperature: nil,
-		Err:         fmt.Errorf("can not find zipcode"),
+		Err:         fmt.Errorf("error when getting address: %w", apperrors.ErrCEPNotFound),
 	}
 
-	handler := handler.NewInputHandler(mockService)
+	handler := handler.NewInputHandler(mockService, logging.Discard())
 
 	body := bytes.NewBufferString(`{"cep": "99999999"}`)
 	req, err := http.NewRequest("POST", "/", body)
@@ -137,7 +146,7 @@ func TestGetTemperatureByCep_InternalServerError(t *testing.T) {
 		Err:         fmt.Errorf("internal server error"),
 	}
 
-	handler := handler.NewInputHandler(mockService)
+	handler := handler.NewInputHandler(mockService, logging.Discard())
 
 	body := bytes.NewBufferString(`{"cep": "00000000"}`)
 	req, err := http.NewRequest("POST", "/", body)
@@ -157,3 +166,86 @@ func TestGetTemperatureByCep_InternalServerError(t *testing.T) {
 		t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), expected)
 	}
 }
+
+func TestGetTemperatureByCep_UpstreamBadPayload(t *testing.T) {
+	mockService := &MockInputService{
+		Temperature: nil,
+		Err:         fmt.Errorf("error when getting temperature: %w", apperrors.BadPayload("Service B", nil, "error parsing json")),
+	}
+
+	handler := handler.NewInputHandler(mockService, logging.Discard())
+
+	body := bytes.NewBufferString(`{"cep": "12345678"}`)
+	req, err := http.NewRequest("POST", "/", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	handler.GetTemperatureByCep(responseRecorder, req)
+
+	if status := responseRecorder.Code; status != http.StatusBadGateway {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadGateway)
+	}
+}
+
+func TestGetForecastByCep(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedStatus  int
+		expectedRequest *model.ForecastRequest
+		expectedBody    string
+	}{
+		{
+			name:            "defaults",
+			body:            `{"cep": "12345678"}`,
+			expectedStatus:  http.StatusOK,
+			expectedRequest: &model.ForecastRequest{Cep: "12345678", Days: 3, Granularity: "daily"},
+			expectedBody:    `{"city":"Cidade","granularity":"daily","forecast":[{"time":"2024-08-30"}]}`,
+		},
+		{
+			name:            "hourly",
+			body:            `{"cep": "12345678", "days": 1, "granularity": "hourly"}`,
+			expectedStatus:  http.StatusOK,
+			expectedRequest: &model.ForecastRequest{Cep: "12345678", Days: 1, Granularity: "hourly"},
+		},
+		{
+			name:           "invalid days",
+			body:           `{"cep": "12345678", "days": 0}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid forecast request: days must be between 1 and 7, got 0",
+		},
+		{
+			name:           "malformed body",
+			body:           `{"cep": 12345678`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid forecast request: the body must be a JSON object with the cep",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockInputService{
+				Forecast: &temperatureServerModel.Forecast{City: "Cidade", Granularity: "daily", Points: []temperatureServerModel.ForecastPoint{{Time: "2024-08-30"}}},
+			}
+
+			handler := handler.NewInputHandler(mockService, logging.Discard())
+
+			responseRecorder := httptest.NewRecorder()
+			handler.GetForecastByCep(responseRecorder, httptest.NewRequest(http.MethodPost, "/forecast", bytes.NewBufferString(test.body)))
+
+			if status := responseRecorder.Code; status != test.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedStatus)
+			}
+
+			if test.expectedRequest != nil && (mockService.Request == nil || *mockService.Request != *test.expectedRequest) {
+				t.Errorf("handler passed unexpected request: got %+v want %+v", mockService.Request, test.expectedRequest)
+			}
+
+			if test.expectedBody != "" && strings.Trim(responseRecorder.Body.String(), "\n") != test.expectedBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
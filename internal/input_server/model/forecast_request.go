@@ -0,0 +1,7 @@
+package model
+
+type ForecastRequest struct {
+	Cep         string `json:"cep"`
+	Days        int    `json:"days"`
+	Granularity string `json:"granularity"`
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type BatchRepository interface {
+	GetTemperatures([]string, context.Context) ([]temperatureServerModel.BatchItem, error)
+}
+
+type batchRepository struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewBatchRepository forwards whole batches to the batch endpoint of Service
+// B. The timeout bounds the whole batch, not each of its zipcodes.
+func NewBatchRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) BatchRepository {
+	return &batchRepository{
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (r *batchRepository) GetTemperatures(ceps []string, ctx context.Context) (_ []temperatureServerModel.BatchItem, err error) {
+	tracer := otel.Tracer("BatchRepository")
+
+	ctx, span := tracer.Start(ctx, "BatchRepository.GetTemperatures")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("Service B", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "Service B request failed", &err, ctx, "size", len(ceps))
+
+	span.SetAttributes(attribute.Int("batch.size", len(ceps)))
+
+	batchURL, err := utils.BuildURL(r.baseURL, []string{"batch"}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when building batch api url: %w", err)
+	}
+
+	body, err := json.Marshal(ceps)
+	if err != nil {
+		return nil, fmt.Errorf("error when encoding batch: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, batchURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport("Service B", err, "error when searching for temperatures of a batch")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromHTTPResponse("Service B", resp, "batch api returned status %d", resp.StatusCode)
+	}
+
+	var items []temperatureServerModel.BatchItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, apperrors.BadPayload("Service B", err, "error parsing json")
+	}
+
+	if len(items) != len(ceps) {
+		return nil, apperrors.BadPayload("Service B", nil, "batch api answered %d items for %d zipcodes", len(items), len(ceps))
+	}
+
+	return items, nil
+}
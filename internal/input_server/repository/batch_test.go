@@ -0,0 +1,76 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+func TestBatchRepository_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/batch" {
+			t.Errorf("Request mismatch: expected POST /batch, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var ceps []string
+		if err := json.NewDecoder(r.Body).Decode(&ceps); err != nil || !reflect.DeepEqual(ceps, []string{"01001000", "123"}) {
+			t.Errorf("Body mismatch: got %v, %v", ceps, err)
+		}
+
+		w.Write([]byte(`[{"cep":"01001000","status":200,"result":{"city":"São Paulo","temp_C":22.4}},{"cep":"123","status":422,"error":"invalid zipcode"}]`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewBatchRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	items, err := repo.GetTemperatures([]string{"01001000", "123"}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if items[0].Status != http.StatusOK || items[0].Temperature.City != "São Paulo" {
+		t.Errorf("Unexpected first item %+v", items[0])
+	}
+
+	if items[1].Status != http.StatusUnprocessableEntity || items[1].Error != "invalid zipcode" {
+		t.Errorf("Unexpected second item %+v", items[1])
+	}
+}
+
+func TestBatchRepository_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"server error", http.StatusInternalServerError, "", apperrors.ErrUpstreamUnavailable},
+		{"missing items", http.StatusOK, `[{"cep":"01001000","status":200}]`, apperrors.ErrUpstreamBadPayload},
+		{"invalid json", http.StatusOK, `{`, apperrors.ErrUpstreamBadPayload},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			repo := repository.NewBatchRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+			if _, err := repo.GetTemperatures([]string{"01001000", "88625000"}, context.Background()); !errors.Is(err, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerBatchRepository struct {
+	next           BatchRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerBatchRepository(next BatchRepository, circuitBreaker *circuitbreaker.CircuitBreaker) BatchRepository {
+	return &circuitBreakerBatchRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerBatchRepository) GetTemperatures(ceps []string, ctx context.Context) ([]temperatureServerModel.BatchItem, error) {
+	tracer := otel.Tracer("CircuitBreakerBatchRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerBatchRepository.GetTemperatures")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	items, err := r.next.GetTemperatures(ceps, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return items, err
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerForecastRepository struct {
+	next           ForecastRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerForecastRepository(next ForecastRepository, circuitBreaker *circuitbreaker.CircuitBreaker) ForecastRepository {
+	return &circuitBreakerForecastRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerForecastRepository) GetForecast(cep string, query temperatureServerModel.ForecastQuery, ctx context.Context) (*temperatureServerModel.Forecast, error) {
+	tracer := otel.Tracer("CircuitBreakerForecastRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerForecastRepository.GetForecast")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	forecast, err := r.next.GetForecast(cep, query, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return forecast, err
+}
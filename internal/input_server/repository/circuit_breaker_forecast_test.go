@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+type CountingForecastRepository struct {
+	Forecast *temperatureServerModel.Forecast
+	Err      error
+	Calls    int
+}
+
+func (m *CountingForecastRepository) GetForecast(string, temperatureServerModel.ForecastQuery, context.Context) (*temperatureServerModel.Forecast, error) {
+	m.Calls++
+	return m.Forecast, m.Err
+}
+
+func TestCircuitBreakerForecastRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingForecastRepository{Err: apperrors.Status("Service B", http.StatusBadGateway, "forecast by cep api returned status 502")}
+
+	breaker := circuitbreaker.New("Service B", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerForecastRepository(next, breaker)
+
+	query := temperatureServerModel.ForecastQuery{Days: 1, Granularity: temperatureServerModel.GranularityDaily}
+
+	repo.GetForecast("12345678", query, context.Background())
+
+	_, err := repo.GetForecast("12345678", query, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetForecast("12345678", query, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
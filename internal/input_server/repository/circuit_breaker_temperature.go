@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerTemperatureRepository struct {
+	next           TemperatureRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerTemperatureRepository(next TemperatureRepository, circuitBreaker *circuitbreaker.CircuitBreaker) TemperatureRepository {
+	return &circuitBreakerTemperatureRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerTemperatureRepository) GetTemperature(zipcode *model.Zipcode, ctx context.Context) (*temperatureServerModel.Temperature, error) {
+	tracer := otel.Tracer("CircuitBreakerTemperatureRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerTemperatureRepository.GetTemperature")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	temperature, err := r.next.GetTemperature(zipcode, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return temperature, err
+}
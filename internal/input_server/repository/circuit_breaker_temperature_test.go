@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+type CountingTemperatureRepository struct {
+	Temperature *temperatureServerModel.Temperature
+	Err         error
+	Calls       int
+}
+
+func (m *CountingTemperatureRepository) GetTemperature(*model.Zipcode, context.Context) (*temperatureServerModel.Temperature, error) {
+	m.Calls++
+	return m.Temperature, m.Err
+}
+
+func TestCircuitBreakerTemperatureRepository_FailFastWhenOpen(t *testing.T) {
+	next := &CountingTemperatureRepository{Err: apperrors.Status("Service B", http.StatusBadGateway, "temperature by cep api returned status 502")}
+
+	breaker := circuitbreaker.New("Service B", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	repo := repository.NewCircuitBreakerTemperatureRepository(next, breaker)
+
+	zipcode := &model.Zipcode{
+		Cep: "12345678",
+	}
+
+	repo.GetTemperature(zipcode, context.Background())
+
+	_, err := repo.GetTemperature(zipcode, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"go.opentelemetry.io/otel"
+)
+
+type ForecastRepository interface {
+	GetForecast(string, temperatureServerModel.ForecastQuery, context.Context) (*temperatureServerModel.Forecast, error)
+}
+
+type forecastRepository struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+func NewForecastRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) ForecastRepository {
+	return &forecastRepository{
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (r *forecastRepository) GetForecast(zipcode string, query temperatureServerModel.ForecastQuery, ctx context.Context) (_ *temperatureServerModel.Forecast, err error) {
+	tracer := otel.Tracer("ForecastRepository")
+
+	ctx, span := tracer.Start(ctx, "ForecastRepository.GetForecast")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("Service B", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "Service B forecast request failed", &err, ctx, "cep", zipcode)
+
+	parsed, err := cep.Parse(zipcode)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{
+		"cep":         {parsed.String()},
+		"days":        {strconv.Itoa(query.Days)},
+		"granularity": {query.Granularity},
+	}
+
+	forecastURL, err := utils.BuildURL(r.baseURL, []string{"forecast"}, params)
+	if err != nil {
+		return nil, fmt.Errorf("error when building forecast by cep api url: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, forecastURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport("Service B", err, "error when searching for forecast by cep")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromHTTPResponse("Service B", resp, "forecast by cep api returned status %d", resp.StatusCode)
+	}
+
+	var forecast temperatureServerModel.Forecast
+	if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+		return nil, apperrors.BadPayload("Service B", err, "error parsing json")
+	}
+
+	return &forecast, nil
+}
@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+func TestForecastRepository_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if r.URL.Path != "/forecast" || query.Get("cep") != "12345678" || query.Get("days") != "2" || query.Get("granularity") != "hourly" {
+			t.Errorf("Unexpected request %v", r.URL)
+		}
+
+		w.Write([]byte(`{"city":"Cidade","granularity":"hourly","forecast":[{"time":"2024-08-30T00:00","temp_C":-1.5}]}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewForecastRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	forecast, err := repo.GetForecast("12345678", temperatureServerModel.ForecastQuery{Days: 2, Granularity: "hourly"}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if forecast.City != "Cidade" || len(forecast.Points) != 1 || *forecast.Points[0].Celsius != -1.5 {
+		t.Errorf("Unexpected forecast %+v", forecast)
+	}
+}
+
+func TestForecastRepository_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cep      string
+		status   int
+		body     string
+		expected error
+	}{
+		{"invalid cep", "1234", http.StatusOK, "", apperrors.ErrInvalidCEP},
+		{"invalid query", "12345678", http.StatusBadRequest, "invalid forecast request: days must be between 1 and 16, got 20", apperrors.ErrInvalidForecast},
+		{"not found", "12345678", http.StatusNotFound, "can not find zipcode", apperrors.ErrCEPNotFound},
+		{"location not found", "12345678", http.StatusNotFound, "can not find location", apperrors.ErrLocationNotFound},
+		{"unknown route", "12345678", http.StatusNotFound, "404 page not found", apperrors.ErrUpstreamUnavailable},
+		{"unavailable", "12345678", http.StatusBadGateway, "upstream unavailable", apperrors.ErrUpstreamUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, test.body, test.status)
+			}))
+			defer server.Close()
+
+			repo := repository.NewForecastRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+			_, err := repo.GetForecast(test.cep, temperatureServerModel.ForecastQuery{Days: 1, Granularity: "daily"}, context.Background())
+			if !errors.Is(err, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
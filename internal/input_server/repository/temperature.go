@@ -4,74 +4,80 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
 	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 )
 
 type TemperatureRepository interface {
-	GetTemperature(*model.Zipcode, context.Context, context.Context) (*temperatureServerModel.Temperature, error)
+	GetTemperature(*model.Zipcode, context.Context) (*temperatureServerModel.Temperature, error)
 }
 
 type temperatureRepository struct {
-	URL string
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
 }
 
-func NewTemperatureRepository(url string) TemperatureRepository {
+func NewTemperatureRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) TemperatureRepository {
 	return &temperatureRepository{
-		URL: url,
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
 	}
 }
 
-func (r *temperatureRepository) GetTemperature(zipcode *model.Zipcode, ctx context.Context, ctxDistributed context.Context) (*temperatureServerModel.Temperature, error) {
+func (r *temperatureRepository) GetTemperature(zipcode *model.Zipcode, ctx context.Context) (_ *temperatureServerModel.Temperature, err error) {
 	tracer := otel.Tracer("TemperatureRepository")
 
-	_, span := tracer.Start(ctx, "TemperatureRepository.GetTemperature")
-	defer span.End()
+	ctx, span := tracer.Start(ctx, "TemperatureRepository.GetTemperature")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("Service B", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "Service B request failed", &err, ctx, "cep", zipcode.Cep)
 
-	ctxDistributed, spanDistributed := tracer.Start(ctxDistributed, "TemperatureRepository.GetTemperature")
-	defer spanDistributed.End()
-
-	cep := zipcode.Cep
-	if cep == "" || len(cep) != 8 || !utils.IsNumber(cep) {
-		return nil, fmt.Errorf("invalid zipcode")
+	parsed, err := cep.Parse(zipcode.Cep)
+	if err != nil {
+		return nil, err
 	}
 
-	var url string
-
-	if os.Getenv("TEST") == "true" {
-		url = r.URL
-	} else {
-		url = fmt.Sprintf(r.URL, cep)
+	temperatureURL, err := utils.BuildURL(r.baseURL, nil, url.Values{"cep": {parsed.String()}})
+	if err != nil {
+		return nil, fmt.Errorf("error when building temperature by cep api url: %w", err)
 	}
 
-	client := &http.Client{}
-	req, _ := http.NewRequest("GET", url, nil)
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	otel.GetTextMapPropagator().Inject(ctxDistributed, propagation.HeaderCarrier(req.Header))
-
-	resp, err := client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, temperatureURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for temperature by cep: %w", err)
+		return nil, fmt.Errorf("error when creating request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("can not find zipcode")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport("Service B", err, "error when searching for temperature by cep")
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("temperature by cep api returned status %d: %w", resp.StatusCode, err)
+		return nil, apperrors.FromHTTPResponse("Service B", resp, "temperature by cep api returned status %d", resp.StatusCode)
 	}
 
 	var temperature temperatureServerModel.Temperature
 	if err := json.NewDecoder(resp.Body).Decode(&temperature); err != nil {
-		return nil, fmt.Errorf("error parsing json: %w", err)
+		return nil, apperrors.BadPayload("Service B", err, "error parsing json")
 	}
 
 	return &temperature, nil
@@ -2,33 +2,43 @@ package repository_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
 	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 func TestTemperatureRepository_Success(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			t.Errorf("Path mismatch: expected %v, got %v", "/", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("cep") != "12345678" {
+			t.Errorf("Query cep mismatch: expected %v, got %v", "12345678", r.URL.Query().Get("cep"))
+		}
+
 		responseBody := `{"city":"Cidade","temp_C":30,"temp_F":86,"temp_K":303.15}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewTemperatureRepository(server.URL)
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "12345678",
 	}
 
-	address, err := repo.GetTemperature(zipcode, context.Background(), context.Background())
+	address, err := repo.GetTemperature(zipcode, context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -58,21 +68,19 @@ func TestTemperatureRepository_Success(t *testing.T) {
 }
 
 func TestTemperatureRepository_InvalidCep(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `{}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewTemperatureRepository(server.URL)
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "0",
 	}
 
-	_, err := repo.GetTemperature(zipcode, context.Background(), context.Background())
+	_, err := repo.GetTemperature(zipcode, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -84,20 +92,18 @@ func TestTemperatureRepository_InvalidCep(t *testing.T) {
 }
 
 func TestTemperatureRepository_ErrorHttp(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "", http.StatusMovedPermanently)
 	}))
 	defer server.Close()
 
-	repo := repository.NewTemperatureRepository(server.URL)
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "12345678",
 	}
 
-	_, err := repo.GetTemperature(zipcode, context.Background(), context.Background())
+	_, err := repo.GetTemperature(zipcode, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -109,20 +115,18 @@ func TestTemperatureRepository_ErrorHttp(t *testing.T) {
 }
 
 func TestTemperatureRepository_NotFindZipcode(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, "Not Found", http.StatusNotFound)
+		http.Error(w, "can not find zipcode", http.StatusNotFound)
 	}))
 	defer server.Close()
 
-	repo := repository.NewTemperatureRepository(server.URL)
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "12345678",
 	}
 
-	_, err := repo.GetTemperature(zipcode, context.Background(), context.Background())
+	_, err := repo.GetTemperature(zipcode, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -134,8 +138,6 @@ func TestTemperatureRepository_NotFindZipcode(t *testing.T) {
 }
 
 func TestTemperatureRepository_NotStatusOK(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	statusServerError := http.StatusInternalServerError
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -143,13 +145,13 @@ func TestTemperatureRepository_NotStatusOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewTemperatureRepository(server.URL)
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "12345678",
 	}
 
-	_, err := repo.GetTemperature(zipcode, context.Background(), context.Background())
+	_, err := repo.GetTemperature(zipcode, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -161,21 +163,19 @@ func TestTemperatureRepository_NotStatusOK(t *testing.T) {
 }
 
 func TestTemperatureRepository_ErrorJsonDecoder(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `error`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewTemperatureRepository(server.URL)
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "12345678",
 	}
 
-	_, err := repo.GetTemperature(zipcode, context.Background(), context.Background())
+	_, err := repo.GetTemperature(zipcode, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -185,3 +185,64 @@ func TestTemperatureRepository_ErrorJsonDecoder(t *testing.T) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
 }
+
+func TestTemperatureRepository_InvalidZipcodeFromService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid zipcode", http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	zipcode := &model.Zipcode{
+		Cep: "12345678",
+	}
+
+	_, err := repo.GetTemperature(zipcode, context.Background())
+	if !errors.Is(err, apperrors.ErrInvalidCEP) {
+		t.Errorf("Expected ErrInvalidCEP, got %v", err)
+	}
+}
+
+func TestTemperatureRepository_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), 10*time.Millisecond, logging.Discard())
+
+	zipcode := &model.Zipcode{
+		Cep: "12345678",
+	}
+
+	_, err := repo.GetTemperature(zipcode, context.Background())
+	if !errors.Is(err, apperrors.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestTemperatureRepository_FormattedCEP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cep") != "01001000" {
+			t.Errorf("Query cep mismatch: expected %v, got %v", "01001000", r.URL.Query().Get("cep"))
+		}
+
+		w.Write([]byte(`{"city":"São Paulo","cep":"01001-000","temp_C":22}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewTemperatureRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	temperature, err := repo.GetTemperature(&model.Zipcode{Cep: " 01.001-000 "}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if temperature.CEP != "01001-000" {
+		t.Errorf("CEP mismatch: expected %v, got %v", "01001-000", temperature.CEP)
+	}
+}
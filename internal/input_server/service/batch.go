@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type BatchService interface {
+	GetTemperatureByCeps([]string, context.Context) []temperatureServerModel.BatchItem
+}
+
+type batchService struct {
+	batchRepository repository.BatchRepository
+	logger          *slog.Logger
+}
+
+func NewBatchService(batchRepository repository.BatchRepository, logger *slog.Logger) BatchService {
+	return &batchService{
+		batchRepository: batchRepository,
+		logger:          logger,
+	}
+}
+
+// GetTemperatureByCeps forwards the batch to Service B in a single call and
+// answers one item per zipcode in the order they were given. When Service B
+// fails as a whole, every zipcode carries its error.
+func (s *batchService) GetTemperatureByCeps(ceps []string, ctx context.Context) []temperatureServerModel.BatchItem {
+	tracer := otel.Tracer("BatchService")
+
+	ctx, span := tracer.Start(ctx, "BatchService.GetTemperatureByCeps")
+	defer span.End()
+
+	items, err := s.batchRepository.GetTemperatures(ceps, ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "batch failed as a whole", "size", len(ceps), "error", err)
+
+		items = make([]temperatureServerModel.BatchItem, len(ceps))
+		for i, cep := range ceps {
+			items[i] = temperatureServerModel.NewBatchItem(cep, nil, err)
+		}
+	}
+
+	failed := 0
+
+	for _, item := range items {
+		if item.Error != "" {
+			failed++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("batch.size", len(ceps)), attribute.Int("batch.failed", failed))
+
+	s.logger.DebugContext(ctx, "batch done", "size", len(ceps), "failed", failed)
+
+	return items
+}
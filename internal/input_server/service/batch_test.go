@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/service"
+	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockBatchRepository struct {
+	Err   error
+	Calls int
+}
+
+func (m *MockBatchRepository) GetTemperatures(ceps []string, ctx context.Context) ([]temperatureServerModel.BatchItem, error) {
+	m.Calls++
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	items := make([]temperatureServerModel.BatchItem, len(ceps))
+	for i, cep := range ceps {
+		items[i] = temperatureServerModel.NewBatchItem(cep, &temperatureServerModel.Temperature{City: "Cidade", Celsius: 30}, nil)
+	}
+
+	return items, nil
+}
+
+func TestBatchService_GetTemperatureByCeps(t *testing.T) {
+	mockBatchRepo := &MockBatchRepository{}
+
+	batchService := service.NewBatchService(mockBatchRepo, logging.Discard())
+
+	items := batchService.GetTemperatureByCeps([]string{"12345678", "87654321", "12345678"}, context.Background())
+
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(items))
+	}
+
+	for i, cep := range []string{"12345678", "87654321", "12345678"} {
+		if items[i].CEP != cep || items[i].Status != http.StatusOK || items[i].Temperature.City != "Cidade" {
+			t.Errorf("Item %d: unexpected %+v", i, items[i])
+		}
+	}
+
+	if mockBatchRepo.Calls != 1 {
+		t.Errorf("Expected the batch to be forwarded in a single call, got %d", mockBatchRepo.Calls)
+	}
+}
+
+func TestBatchService_FailedAsAWhole(t *testing.T) {
+	mockBatchRepo := &MockBatchRepository{Err: apperrors.NewUpstreamError("Service B", apperrors.ErrCircuitOpen, nil, "open")}
+
+	batchService := service.NewBatchService(mockBatchRepo, logging.Discard())
+
+	items := batchService.GetTemperatureByCeps([]string{"12345678", "87654321"}, context.Background())
+
+	for i, item := range items {
+		if item.Status != http.StatusServiceUnavailable || item.Temperature != nil || item.Error == "" {
+			t.Errorf("Item %d: expected a failed item, got %+v", i, item)
+		}
+	}
+}
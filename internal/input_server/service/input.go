@@ -2,42 +2,69 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/repository"
 	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"go.opentelemetry.io/otel"
 )
 
 type InputService interface {
-	GetTemperatureByCep(*model.Zipcode, context.Context, context.Context) (*temperatureServerModel.Temperature, error)
+	GetTemperatureByCep(*model.Zipcode, context.Context) (*temperatureServerModel.Temperature, error)
+	GetForecastByCep(*model.ForecastRequest, context.Context) (*temperatureServerModel.Forecast, error)
 }
 
 type inputService struct {
 	temperatureRepository repository.TemperatureRepository
+	forecastRepository    repository.ForecastRepository
+	logger                *slog.Logger
 }
 
 func NewInputService(
 	temperatureRepository repository.TemperatureRepository,
+	forecastRepository repository.ForecastRepository,
+	logger *slog.Logger,
 ) InputService {
 	return &inputService{
 		temperatureRepository: temperatureRepository,
+		forecastRepository:    forecastRepository,
+		logger:                logger,
 	}
 }
 
-func (s *inputService) GetTemperatureByCep(zipcode *model.Zipcode, ctx context.Context, ctxDistributed context.Context) (*temperatureServerModel.Temperature, error) {
+func (s *inputService) GetTemperatureByCep(zipcode *model.Zipcode, ctx context.Context) (_ *temperatureServerModel.Temperature, err error) {
 	tracer := otel.Tracer("InputService")
 
 	ctx, span := tracer.Start(ctx, "InputService.GetTemperatureByCep")
-	defer span.End()
+	defer telemetry.EndSpan(span, &err)
 
-	ctxDistributed, spanDistributed := tracer.Start(ctxDistributed, "InputService.GetTemperatureByCep")
-	defer spanDistributed.End()
-
-	temperature, err := s.temperatureRepository.GetTemperature(zipcode, ctx, ctxDistributed)
+	temperature, err := s.temperatureRepository.GetTemperature(zipcode, ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error when getting temperature for zipcode %s: %w", zipcode.Cep, err)
 	}
 
+	s.logger.DebugContext(ctx, "temperature found", "cep", zipcode.Cep, "city", temperature.City)
+
 	return temperature, nil
 }
+
+func (s *inputService) GetForecastByCep(request *model.ForecastRequest, ctx context.Context) (_ *temperatureServerModel.Forecast, err error) {
+	tracer := otel.Tracer("InputService")
+
+	ctx, span := tracer.Start(ctx, "InputService.GetForecastByCep")
+	defer telemetry.EndSpan(span, &err)
+
+	query := temperatureServerModel.ForecastQuery{Days: request.Days, Granularity: request.Granularity}
+
+	forecast, err := s.forecastRepository.GetForecast(request.Cep, query, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting forecast for zipcode %s: %w", request.Cep, err)
+	}
+
+	s.logger.DebugContext(ctx, "forecast found", "cep", request.Cep, "city", forecast.City)
+
+	return forecast, nil
+}
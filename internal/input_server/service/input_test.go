@@ -9,6 +9,7 @@ import (
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/input_server/service"
 	temperatureServerModel "github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 type MockTemperatureRepository struct {
@@ -16,20 +17,20 @@ type MockTemperatureRepository struct {
 	Err         error
 }
 
-func (m *MockTemperatureRepository) GetTemperature(*model.Zipcode, context.Context, context.Context) (*temperatureServerModel.Temperature, error) {
+func (m *MockTemperatureRepository) GetTemperature(*model.Zipcode, context.Context) (*temperatureServerModel.Temperature, error) {
 	return m.Temperature, m.Err
 }
 
 func TestInputService_Success(t *testing.T) {
 	mockTemperatureRepo := &MockTemperatureRepository{Temperature: &temperatureServerModel.Temperature{City: "Cidade", Celsius: 30.0, Fahrenheit: 86.0, Kelvin: 303.15}}
 
-	service := service.NewInputService(mockTemperatureRepo)
+	service := service.NewInputService(mockTemperatureRepo, nil, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "12345678",
 	}
 
-	temperature, err := service.GetTemperatureByCep(zipcode, context.Background(), context.Background())
+	temperature, err := service.GetTemperatureByCep(zipcode, context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -63,13 +64,13 @@ func TestInputService_Error(t *testing.T) {
 
 	mockTemperatureRepo := &MockTemperatureRepository{Err: fmt.Errorf(expectedErrorMsg)}
 
-	service := service.NewInputService(mockTemperatureRepo)
+	service := service.NewInputService(mockTemperatureRepo, nil, logging.Discard())
 
 	zipcode := &model.Zipcode{
 		Cep: "0",
 	}
 
-	_, err := service.GetTemperatureByCep(zipcode, context.Background(), context.Background())
+	_, err := service.GetTemperatureByCep(zipcode, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -78,3 +79,33 @@ func TestInputService_Error(t *testing.T) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
 }
+
+type MockForecastRepository struct {
+	Forecast *temperatureServerModel.Forecast
+	Query    temperatureServerModel.ForecastQuery
+	Err      error
+}
+
+func (m *MockForecastRepository) GetForecast(cep string, query temperatureServerModel.ForecastQuery, ctx context.Context) (*temperatureServerModel.Forecast, error) {
+	m.Query = query
+	return m.Forecast, m.Err
+}
+
+func TestInputService_GetForecastByCep(t *testing.T) {
+	mockForecastRepo := &MockForecastRepository{Forecast: &temperatureServerModel.Forecast{City: "Cidade", Granularity: "hourly"}}
+
+	service := service.NewInputService(nil, mockForecastRepo, logging.Discard())
+
+	forecast, err := service.GetForecastByCep(&model.ForecastRequest{Cep: "12345678", Days: 2, Granularity: "hourly"}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if forecast.City != "Cidade" {
+		t.Errorf("Expected City %v, got %v", "Cidade", forecast.City)
+	}
+
+	if mockForecastRepo.Query != (temperatureServerModel.ForecastQuery{Days: 2, Granularity: "hourly"}) {
+		t.Errorf("Unexpected query %+v", mockForecastRepo.Query)
+	}
+}
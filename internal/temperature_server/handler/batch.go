@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+type BatchHandler struct {
+	batchService service.BatchService
+	maxSize      int
+	timeout      time.Duration
+	logger       *slog.Logger
+}
+
+func NewBatchHandler(batchService service.BatchService, maxSize int, timeout time.Duration, logger *slog.Logger) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+		maxSize:      maxSize,
+		timeout:      timeout,
+		logger:       logger,
+	}
+}
+
+// GetWeatherByCEPs reads a JSON array of zipcodes and answers 200 with one
+// item per zipcode, each carrying its own status. The zipcodes still pending
+// when the timeout elapses are answered as timed out, so the partial answer is
+// written before the server gives up on the response.
+func (h *BatchHandler) GetWeatherByCEPs(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("BatchHandler")
+
+	ctx, span := tracer.Start(r.Context(), "BatchHandler.GetWeatherByCEPs")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	items, err := h.getWeather(r.Body, ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+func (h *BatchHandler) getWeather(body io.Reader, ctx context.Context) ([]model.BatchItem, error) {
+	ceps, err := model.DecodeBatch(body, h.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.batchService.GetWeatherByCEPs(ceps, ctx), nil
+}
@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/handler"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockBatchService struct{}
+
+func (m *MockBatchService) GetWeatherByCEPs(ceps []string, ctx context.Context) []model.BatchItem {
+	items := make([]model.BatchItem, len(ceps))
+	for i, cep := range ceps {
+		if cep == "123" {
+			items[i] = model.NewBatchItem(cep, nil, apperrors.ErrInvalidCEP)
+			continue
+		}
+
+		items[i] = model.NewBatchItem(cep, &model.Temperature{City: "São Paulo", Celsius: 25, Fahrenheit: 77, Kelvin: 298}, nil)
+	}
+
+	return items
+}
+
+func TestGetWeatherByCEPs(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "mixed results",
+			body:           `["01001000","123"]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"cep":"01001000","status":200,"result":{"city":"São Paulo","temp_C":25,"temp_F":77,"temp_K":298}},{"cep":"123","status":422,"error":"invalid zipcode"}]`,
+		},
+		{
+			name:           "empty",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid batch request: at least one zipcode is required",
+		},
+		{
+			name:           "too many",
+			body:           `["01001000","01001001","01001002"]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid batch request: at most 2 zipcodes are allowed, got 3",
+		},
+		{
+			name:           "not an array",
+			body:           `{"cep":"01001000"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid batch request: the body must be a JSON array of zipcodes",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := handler.NewBatchHandler(&MockBatchService{}, 2, time.Second, logging.Discard())
+
+			responseRecorder := httptest.NewRecorder()
+			handler.GetWeatherByCEPs(responseRecorder, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(test.body)))
+
+			if status := responseRecorder.Code; status != test.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedStatus)
+			}
+
+			if strings.Trim(responseRecorder.Body.String(), "\n") != test.expectedBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
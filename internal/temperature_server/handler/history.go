@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+)
+
+type HistoryHandler struct {
+	historyService service.HistoryService
+	logger         *slog.Logger
+}
+
+func NewHistoryHandler(historyService service.HistoryService, logger *slog.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+		logger:         logger,
+	}
+}
+
+func (h *HistoryHandler) GetHistoryByCEP(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("HistoryHandler")
+
+	ctx, span := tracer.Start(r.Context(), "HistoryHandler.GetHistoryByCEP")
+	defer span.End()
+
+	cep := r.URL.Query().Get("cep")
+	span.SetAttributes(telemetry.CEPAttribute(cep))
+
+	history, err := h.getHistory(cep, r.URL.Query(), ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *HistoryHandler) getHistory(cep string, params url.Values, ctx context.Context) (*model.History, error) {
+	query, err := parseHistoryQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.historyService.GetHistoryByCEP(cep, query, ctx)
+}
+
+// parseHistoryQuery reads the start and end dates, both required and
+// included in the range.
+func parseHistoryQuery(params url.Values) (model.HistoryQuery, error) {
+	var query model.HistoryQuery
+
+	for _, date := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{"start", &query.Start},
+		{"end", &query.End},
+	} {
+		parsed, err := time.Parse(model.DateLayout, params.Get(date.name))
+		if err != nil {
+			return query, fmt.Errorf("%w: %s must be a date as YYYY-MM-DD, got %q", apperrors.ErrInvalidHistory, date.name, params.Get(date.name))
+		}
+
+		*date.value = parsed
+	}
+
+	return query, query.Validate(time.Now().UTC())
+}
@@ -0,0 +1,79 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/handler"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockHistoryService struct {
+	History *model.History
+	Query   model.HistoryQuery
+	Err     error
+}
+
+func (m *MockHistoryService) GetHistoryByCEP(cep string, query model.HistoryQuery, ctx context.Context) (*model.History, error) {
+	m.Query = query
+	return m.History, m.Err
+}
+
+func TestGetHistoryByCEP(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "valid range",
+			target:         "/history?cep=88625000&start=2024-07-01&end=2024-07-01",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"city":"Urupema","start":"2024-07-01","end":"2024-07-01","history":[{"date":"2024-07-01"}]}`,
+		},
+		{
+			name:           "missing end",
+			target:         "/history?cep=88625000&start=2024-07-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid history request: end must be a date as YYYY-MM-DD, got ""`,
+		},
+		{
+			name:           "invalid date",
+			target:         "/history?cep=88625000&start=01/07/2024&end=2024-07-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid history request: start must be a date as YYYY-MM-DD, got "01/07/2024"`,
+		},
+		{
+			name:           "too long",
+			target:         "/history?cep=88625000&start=2020-01-01&end=2023-01-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid history request: range must be at most 366 days, got 1097",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockHistoryService{
+				History: &model.History{City: "Urupema", Start: "2024-07-01", End: "2024-07-01", Days: []model.HistoryDay{{Date: "2024-07-01"}}},
+			}
+
+			handler := handler.NewHistoryHandler(mockService, logging.Discard())
+
+			responseRecorder := httptest.NewRecorder()
+			handler.GetHistoryByCEP(responseRecorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			if status := responseRecorder.Code; status != test.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedStatus)
+			}
+
+			if strings.Trim(responseRecorder.Body.String(), "\n") != test.expectedBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
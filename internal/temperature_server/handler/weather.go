@@ -1,62 +1,105 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 )
 
 type WeatherHandler struct {
 	weatherService service.WeatherService
+	logger         *slog.Logger
 }
 
-func NewWeatherHandler(weatherService service.WeatherService) *WeatherHandler {
+func NewWeatherHandler(weatherService service.WeatherService, logger *slog.Logger) *WeatherHandler {
 	return &WeatherHandler{
 		weatherService: weatherService,
+		logger:         logger,
 	}
 }
 
 func (h *WeatherHandler) GetWeatherByCEP(w http.ResponseWriter, r *http.Request) {
 	tracer := otel.Tracer("WeatherHandler")
 
-	ctx := r.Context()
-	ctxDistributed := otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+	ctx, span := tracer.Start(r.Context(), "WeatherHandler.GetWeatherByCEP")
+	defer span.End()
 
-	ctx, spanRoute := tracer.Start(ctx, "GET /")
-	defer spanRoute.End()
+	cep := r.URL.Query().Get("cep")
+	span.SetAttributes(telemetry.CEPAttribute(cep))
 
-	ctx, span := tracer.Start(ctx, "WeatherHandler.GetWeatherByCEP")
-	defer span.End()
+	temperature, err := h.weatherService.GetWeatherByCEP(cep, ctx)
+	if err != nil {
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
+		return
+	}
 
-	ctxDistributed, spanDistributedRoute := tracer.Start(ctxDistributed, "GET /")
-	defer spanDistributedRoute.End()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(temperature)
+}
+
+func (h *WeatherHandler) GetForecastByCEP(w http.ResponseWriter, r *http.Request) {
+	tracer := otel.Tracer("WeatherHandler")
 
-	ctxDistributed, spanDistributed := tracer.Start(ctxDistributed, "WeatherHandler.GetWeatherByCEP")
-	defer spanDistributed.End()
+	ctx, span := tracer.Start(r.Context(), "WeatherHandler.GetForecastByCEP")
+	defer span.End()
 
 	cep := r.URL.Query().Get("cep")
+	span.SetAttributes(telemetry.CEPAttribute(cep))
 
-	temperature, err := h.weatherService.GetWeatherByCEP(cep, ctx, ctxDistributed)
+	forecast, err := h.getForecast(cep, r.URL.Query(), ctx)
 	if err != nil {
-		var errorStatusCode int
-
-		switch err.Error() {
-		case "invalid zipcode":
-			errorStatusCode = http.StatusUnprocessableEntity
-		case "can not find zipcode":
-			errorStatusCode = http.StatusNotFound
-		default:
-			errorStatusCode = http.StatusInternalServerError
+		telemetry.RecordError(span, err)
+		logging.Error(h.logger, "request failed", &err, ctx, "status", apperrors.HTTPStatus(err))
+		apperrors.WriteHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(forecast)
+}
+
+func (h *WeatherHandler) getForecast(cep string, params url.Values, ctx context.Context) (*model.Forecast, error) {
+	query, err := parseForecastQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.weatherService.GetForecastByCEP(cep, query, ctx)
+}
+
+// parseForecastQuery reads the days and granularity parameters, defaulting to
+// the daily forecast of the next days.
+func parseForecastQuery(params url.Values) (model.ForecastQuery, error) {
+	query := model.ForecastQuery{
+		Days:        model.DefaultForecastDays,
+		Granularity: model.GranularityDaily,
+	}
+
+	if days := params.Get("days"); days != "" {
+		parsed, err := strconv.Atoi(days)
+		if err != nil {
+			return query, fmt.Errorf("%w: days must be a number, got %q", apperrors.ErrInvalidForecast, days)
 		}
 
-		http.Error(w, err.Error(), errorStatusCode)
+		query.Days = parsed
+	}
 
-		return
+	if granularity := params.Get("granularity"); granularity != "" {
+		query.Granularity = granularity
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(temperature)
+	return query, query.Validate()
 }
@@ -10,24 +10,33 @@ import (
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/handler"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 type MockWeatherService struct {
 	Temperature *model.Temperature
+	Forecast    *model.Forecast
+	Query       model.ForecastQuery
 	Err         error
 }
 
-func (m *MockWeatherService) GetWeatherByCEP(string, context.Context, context.Context) (*model.Temperature, error) {
+func (m *MockWeatherService) GetWeatherByCEP(string, context.Context) (*model.Temperature, error) {
 	return m.Temperature, m.Err
 }
 
+func (m *MockWeatherService) GetForecastByCEP(cep string, query model.ForecastQuery, ctx context.Context) (*model.Forecast, error) {
+	m.Query = query
+	return m.Forecast, m.Err
+}
+
 func TestGetWeatherByCEP_ValidCEP(t *testing.T) {
 	mockService := &MockWeatherService{
 		Temperature: &model.Temperature{City: "Cidade", Celsius: 30, Fahrenheit: 86, Kelvin: 303.15},
 		Err:         nil,
 	}
 
-	handler := handler.NewWeatherHandler(mockService)
+	handler := handler.NewWeatherHandler(mockService, logging.Discard())
 
 	req, err := http.NewRequest("GET", "/?cep=12345678", nil)
 	if err != nil {
@@ -50,10 +59,10 @@ func TestGetWeatherByCEP_ValidCEP(t *testing.T) {
 func TestGetWeatherByCEP_InvalidCEP(t *testing.T) {
 	mockService := &MockWeatherService{
 		Temperature: nil,
-		Err:         fmt.Errorf("invalid zipcode"),
+		Err:         fmt.Errorf("error when getting address: %w", apperrors.ErrInvalidCEP),
 	}
 
-	handler := handler.NewWeatherHandler(mockService)
+	handler := handler.NewWeatherHandler(mockService, logging.Discard())
 
 	req, err := http.NewRequest("GET", "/?cep=12345-678", nil)
 	if err != nil {
@@ -76,10 +85,10 @@ func TestGetWeatherByCEP_InvalidCEP(t *testing.T) {
 func TestGetWeatherByCEP_NotFound(t *testing.T) {
 	mockService := &MockWeatherService{
 		Temperature: nil,
-		Err:         fmt.Errorf("can not find zipcode"),
+		Err:         fmt.Errorf("error when getting address: %w", apperrors.ErrCEPNotFound),
 	}
 
-	handler := handler.NewWeatherHandler(mockService)
+	handler := handler.NewWeatherHandler(mockService, logging.Discard())
 
 	req, err := http.NewRequest("GET", "/?cep=99999999", nil)
 	if err != nil {
@@ -105,7 +114,7 @@ func TestGetWeatherByCEP_InternalServerError(t *testing.T) {
 		Err:         fmt.Errorf("internal server error"),
 	}
 
-	handler := handler.NewWeatherHandler(mockService)
+	handler := handler.NewWeatherHandler(mockService, logging.Discard())
 
 	req, err := http.NewRequest("GET", "/?cep=00000000", nil)
 	if err != nil {
@@ -124,3 +133,114 @@ func TestGetWeatherByCEP_InternalServerError(t *testing.T) {
 		t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), expected)
 	}
 }
+
+func TestGetWeatherByCEP_UpstreamUnavailable(t *testing.T) {
+	mockService := &MockWeatherService{
+		Temperature: nil,
+		Err:         fmt.Errorf("error when getting address: %w", apperrors.Status("ViaCEP", http.StatusServiceUnavailable, "ViaCEP api returned status %d", http.StatusServiceUnavailable)),
+	}
+
+	handler := handler.NewWeatherHandler(mockService, logging.Discard())
+
+	req, err := http.NewRequest("GET", "/?cep=12345678", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	handler.GetWeatherByCEP(responseRecorder, req)
+
+	if status := responseRecorder.Code; status != http.StatusBadGateway {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadGateway)
+	}
+}
+
+func TestGetWeatherByCEP_Timeout(t *testing.T) {
+	mockService := &MockWeatherService{
+		Temperature: nil,
+		Err:         fmt.Errorf("error when getting address: %w", apperrors.Transport("ViaCEP", context.DeadlineExceeded, "error when searching for zipcode information")),
+	}
+
+	handler := handler.NewWeatherHandler(mockService, logging.Discard())
+
+	req, err := http.NewRequest("GET", "/?cep=12345678", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	handler.GetWeatherByCEP(responseRecorder, req)
+
+	if status := responseRecorder.Code; status != http.StatusGatewayTimeout {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusGatewayTimeout)
+	}
+}
+
+func TestGetForecastByCEP(t *testing.T) {
+	maxCelsius := 25.0
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedQuery  model.ForecastQuery
+		expectedBody   string
+	}{
+		{
+			name:           "defaults",
+			target:         "/forecast?cep=12345678",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  model.ForecastQuery{Days: 3, Granularity: "daily"},
+			expectedBody:   `{"city":"Cidade","granularity":"daily","forecast":[{"time":"2024-08-30","max_temp_C":25}]}`,
+		},
+		{
+			name:           "hourly",
+			target:         "/forecast?cep=12345678&days=2&granularity=hourly",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  model.ForecastQuery{Days: 2, Granularity: "hourly"},
+		},
+		{
+			name:           "days not a number",
+			target:         "/forecast?cep=12345678&days=two",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid forecast request: days must be a number, got "two"`,
+		},
+		{
+			name:           "too many days",
+			target:         "/forecast?cep=12345678&days=30",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid forecast request: days must be between 1 and 7, got 30",
+		},
+		{
+			name:           "unknown granularity",
+			target:         "/forecast?cep=12345678&granularity=weekly",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid forecast request: granularity must be hourly or daily, got "weekly"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockWeatherService{
+				Forecast: &model.Forecast{City: "Cidade", Granularity: "daily", Points: []model.ForecastPoint{{Time: "2024-08-30", MaxCelsius: &maxCelsius}}},
+			}
+
+			handler := handler.NewWeatherHandler(mockService, logging.Discard())
+
+			responseRecorder := httptest.NewRecorder()
+			handler.GetForecastByCEP(responseRecorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			if status := responseRecorder.Code; status != test.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedStatus)
+			}
+
+			if test.expectedStatus == http.StatusOK && mockService.Query != test.expectedQuery {
+				t.Errorf("handler passed unexpected query: got %+v want %+v", mockService.Query, test.expectedQuery)
+			}
+
+			if test.expectedBody != "" && strings.Trim(responseRecorder.Body.String(), "\n") != test.expectedBody {
+				t.Errorf("handler returned unexpected body: got %v want %v", responseRecorder.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package model
 
+// Address keeps the field names of ViaCEP whatever the provider that answered
+// it, which is named by Provider, as in viacep.
 type Address struct {
 	PostalCode string `json:"cep"`
 	Street     string `json:"logradouro"`
@@ -7,4 +9,5 @@ type Address struct {
 	District   string `json:"bairro"`
 	City       string `json:"localidade"`
 	State      string `json:"uf"`
+	Provider   string `json:"provider,omitempty"`
 }
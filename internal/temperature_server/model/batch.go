@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+// BatchItem is the answer for one zipcode of a batch, holding either its
+// temperature or the status and message it would have been answered with
+// alone.
+type BatchItem struct {
+	CEP         string       `json:"cep"`
+	Status      int          `json:"status"`
+	Temperature *Temperature `json:"result,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+func NewBatchItem(cep string, temperature *Temperature, err error) BatchItem {
+	if err != nil {
+		return BatchItem{CEP: cep, Status: apperrors.HTTPStatus(err), Error: apperrors.HTTPMessage(err)}
+	}
+
+	return BatchItem{CEP: cep, Status: http.StatusOK, Temperature: temperature}
+}
+
+// DecodeBatch reads the JSON array of zipcodes of a batch request and
+// validates it.
+func DecodeBatch(body io.Reader, maxSize int) ([]string, error) {
+	var ceps []string
+
+	if err := json.NewDecoder(body).Decode(&ceps); err != nil {
+		return nil, fmt.Errorf("%w: the body must be a JSON array of zipcodes", apperrors.ErrInvalidBatch)
+	}
+
+	return ceps, ValidateBatch(ceps, maxSize)
+}
+
+// ValidateBatch checks the size of a batch before any lookup, so an oversized
+// request is refused as a whole.
+func ValidateBatch(ceps []string, maxSize int) error {
+	if len(ceps) == 0 {
+		return fmt.Errorf("%w: at least one zipcode is required", apperrors.ErrInvalidBatch)
+	}
+
+	if len(ceps) > maxSize {
+		return fmt.Errorf("%w: at most %d zipcodes are allowed, got %d", apperrors.ErrInvalidBatch, maxSize, len(ceps))
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package model
+
+import "math"
+
+// The descriptions of the WMO weather interpretation codes (WW), as answered
+// by open-meteo. The codes of the other providers are mapped to them.
+var weatherCodeDescriptions = map[int]struct{ en, ptBR string }{
+	0:  {"Clear sky", "Céu limpo"},
+	1:  {"Mainly clear", "Predominantemente limpo"},
+	2:  {"Partly cloudy", "Parcialmente nublado"},
+	3:  {"Overcast", "Nublado"},
+	45: {"Fog", "Nevoeiro"},
+	48: {"Depositing rime fog", "Nevoeiro com geada"},
+	51: {"Light drizzle", "Garoa fraca"},
+	53: {"Moderate drizzle", "Garoa moderada"},
+	55: {"Dense drizzle", "Garoa intensa"},
+	56: {"Light freezing drizzle", "Garoa congelante fraca"},
+	57: {"Dense freezing drizzle", "Garoa congelante intensa"},
+	61: {"Slight rain", "Chuva fraca"},
+	63: {"Moderate rain", "Chuva moderada"},
+	65: {"Heavy rain", "Chuva forte"},
+	66: {"Light freezing rain", "Chuva congelante fraca"},
+	67: {"Heavy freezing rain", "Chuva congelante forte"},
+	71: {"Slight snow fall", "Neve fraca"},
+	73: {"Moderate snow fall", "Neve moderada"},
+	75: {"Heavy snow fall", "Neve forte"},
+	77: {"Snow grains", "Grãos de neve"},
+	80: {"Slight rain showers", "Pancadas de chuva fracas"},
+	81: {"Moderate rain showers", "Pancadas de chuva moderadas"},
+	82: {"Violent rain showers", "Pancadas de chuva violentas"},
+	85: {"Slight snow showers", "Pancadas de neve fracas"},
+	86: {"Heavy snow showers", "Pancadas de neve fortes"},
+	95: {"Thunderstorm", "Trovoada"},
+	96: {"Thunderstorm with slight hail", "Trovoada com granizo fraco"},
+	99: {"Thunderstorm with heavy hail", "Trovoada com granizo forte"},
+}
+
+// NewCondition describes a WMO weather code in English and Brazilian
+// Portuguese, returning nil for unknown codes.
+func NewCondition(code int) *Condition {
+	description, ok := weatherCodeDescriptions[code]
+	if !ok {
+		return nil
+	}
+
+	return &Condition{
+		Code: code,
+		Description: map[string]string{
+			"en":    description.en,
+			"pt-BR": description.ptBR,
+		},
+	}
+}
+
+var cardinalDirections = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// CardinalDirection names the compass point closest to a direction in
+// degrees, as in 0 for N and 135 for SE.
+func CardinalDirection(degrees float64) string {
+	index := int(math.Round(math.Mod(math.Mod(degrees, 360)+360, 360)/45)) % len(cardinalDirections)
+
+	return cardinalDirections[index]
+}
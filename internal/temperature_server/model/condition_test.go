@@ -0,0 +1,44 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+)
+
+func TestNewCondition(t *testing.T) {
+	condition := model.NewCondition(95)
+	if condition == nil {
+		t.Fatal("Expected a condition, got nil")
+	}
+
+	if condition.Description["en"] != "Thunderstorm" || condition.Description["pt-BR"] != "Trovoada" {
+		t.Errorf("Unexpected descriptions %v", condition.Description)
+	}
+
+	if model.NewCondition(42) != nil {
+		t.Error("Expected nil for an unknown code")
+	}
+}
+
+func TestCardinalDirection(t *testing.T) {
+	tests := []struct {
+		degrees  float64
+		expected string
+	}{
+		{0, "N"},
+		{22, "N"},
+		{23, "NE"},
+		{135, "SE"},
+		{190, "S"},
+		{350, "N"},
+		{360, "N"},
+		{-90, "W"},
+	}
+
+	for _, test := range tests {
+		if got := model.CardinalDirection(test.degrees); got != test.expected {
+			t.Errorf("CardinalDirection(%v): expected %s, got %s", test.degrees, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+const (
+	GranularityHourly = "hourly"
+	GranularityDaily  = "daily"
+
+	DefaultForecastDays = 3
+	MaxForecastDays     = 7
+)
+
+type ForecastQuery struct {
+	Days        int
+	Granularity string
+}
+
+func (q ForecastQuery) Validate() error {
+	if q.Days < 1 || q.Days > MaxForecastDays {
+		return fmt.Errorf("%w: days must be between 1 and %d, got %d", apperrors.ErrInvalidForecast, MaxForecastDays, q.Days)
+	}
+
+	if q.Granularity != GranularityHourly && q.Granularity != GranularityDaily {
+		return fmt.Errorf("%w: granularity must be %s or %s, got %q", apperrors.ErrInvalidForecast, GranularityHourly, GranularityDaily, q.Granularity)
+	}
+
+	return nil
+}
+
+// Forecast is a time series in the local time of the location. Hourly points
+// carry the temperature and daily points the minimum and maximum, both in
+// Celsius.
+type Forecast struct {
+	City        string          `json:"city"`
+	Granularity string          `json:"granularity"`
+	Points      []ForecastPoint `json:"forecast"`
+}
+
+type ForecastPoint struct {
+	Time                     string     `json:"time"`
+	Celsius                  *float64   `json:"temp_C,omitempty"`
+	MinCelsius               *float64   `json:"min_temp_C,omitempty"`
+	MaxCelsius               *float64   `json:"max_temp_C,omitempty"`
+	PrecipitationProbability *float64   `json:"precipitation_probability,omitempty"`
+	Condition                *Condition `json:"condition,omitempty"`
+}
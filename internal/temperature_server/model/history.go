@@ -0,0 +1,72 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+const (
+	DateLayout = "2006-01-02"
+
+	MaxHistoryDays = 366
+)
+
+// The first day answered by the weather archive.
+var historyStart = time.Date(1940, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type HistoryQuery struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Days is the length of the range, both ends included.
+func (q HistoryQuery) Days() int {
+	return int(q.End.Sub(q.Start).Hours()/24) + 1
+}
+
+// Validate accepts ranges that ended before today, as the archive only holds
+// past days.
+func (q HistoryQuery) Validate(today time.Time) error {
+	if q.End.Before(q.Start) {
+		return fmt.Errorf("%w: start %s is after end %s", apperrors.ErrInvalidHistory, q.Start.Format(DateLayout), q.End.Format(DateLayout))
+	}
+
+	if q.Start.Before(historyStart) {
+		return fmt.Errorf("%w: start must not be before %s", apperrors.ErrInvalidHistory, historyStart.Format(DateLayout))
+	}
+
+	if !q.End.Before(today.Truncate(24 * time.Hour)) {
+		return fmt.Errorf("%w: end must be before today", apperrors.ErrInvalidHistory)
+	}
+
+	if q.Days() > MaxHistoryDays {
+		return fmt.Errorf("%w: range must be at most %d days, got %d", apperrors.ErrInvalidHistory, MaxHistoryDays, q.Days())
+	}
+
+	return nil
+}
+
+type History struct {
+	City  string       `json:"city"`
+	Start string       `json:"start"`
+	End   string       `json:"end"`
+	Days  []HistoryDay `json:"history"`
+}
+
+// HistoryDay holds the daily aggregates of a past day, in Celsius and
+// millimeters. The values are nil while the archive has no data for the day.
+type HistoryDay struct {
+	Date            string   `json:"date"`
+	MinCelsius      *float64 `json:"min_temp_C,omitempty"`
+	MaxCelsius      *float64 `json:"max_temp_C,omitempty"`
+	MeanCelsius     *float64 `json:"mean_temp_C,omitempty"`
+	PrecipitationMm *float64 `json:"precipitation_mm,omitempty"`
+}
+
+// Complete reports whether the archive answered every aggregate of the day,
+// after which they no longer change.
+func (d HistoryDay) Complete() bool {
+	return d.MinCelsius != nil && d.MaxCelsius != nil && d.MeanCelsius != nil && d.PrecipitationMm != nil
+}
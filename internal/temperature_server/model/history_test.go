@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+func TestHistoryQueryValidate(t *testing.T) {
+	today := time.Date(2024, time.August, 30, 15, 0, 0, 0, time.UTC)
+
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(model.DateLayout, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return parsed
+	}
+
+	tests := []struct {
+		name        string
+		start, end  string
+		expectedErr string
+	}{
+		{name: "single day", start: "2024-08-29", end: "2024-08-29"},
+		{name: "future end", start: "2024-08-01", end: "2024-12-31", expectedErr: "end must be before today"},
+		{name: "max range", start: "2023-08-29", end: "2024-08-28"},
+		{name: "too long", start: "2023-08-28", end: "2024-08-28", expectedErr: "range must be at most 366 days, got 367"},
+		{name: "reversed", start: "2024-08-20", end: "2024-08-10", expectedErr: "start 2024-08-20 is after end 2024-08-10"},
+		{name: "today", start: "2024-08-29", end: "2024-08-30", expectedErr: "end must be before today"},
+		{name: "before the archive", start: "1939-12-31", end: "1940-01-02", expectedErr: "start must not be before 1940-01-01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := model.HistoryQuery{Start: date(test.start), End: date(test.end)}.Validate(today)
+
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, apperrors.ErrInvalidHistory) || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
@@ -1,8 +1,34 @@
 package model
 
+// Temperature is the answer of Service B. CEP is the zipcode as written by
+// the Correios and Address the one answered by ViaCEP. The conditions besides
+// the temperature are left out when the weather provider did not return them.
 type Temperature struct {
-	City       string  `json:"city"`
+	City       string     `json:"city"`
+	CEP        string     `json:"cep,omitempty"`
+	Address    *Address   `json:"address,omitempty"`
+	Celsius    float64    `json:"temp_C"`
+	Fahrenheit float64    `json:"temp_F"`
+	Kelvin     float64    `json:"temp_K"`
+	FeelsLike  *FeelsLike `json:"feels_like,omitempty"`
+	Humidity   *float64   `json:"humidity,omitempty"`
+	Wind       *Wind      `json:"wind,omitempty"`
+	Condition  *Condition `json:"condition,omitempty"`
+}
+
+type FeelsLike struct {
 	Celsius    float64 `json:"temp_C"`
 	Fahrenheit float64 `json:"temp_F"`
 	Kelvin     float64 `json:"temp_K"`
 }
+
+type Wind struct {
+	SpeedKmh  float64  `json:"speed_kmh"`
+	Direction *float64 `json:"direction_deg,omitempty"`
+	Cardinal  string   `json:"direction,omitempty"`
+}
+
+type Condition struct {
+	Code        int               `json:"code"`
+	Description map[string]string `json:"description"`
+}
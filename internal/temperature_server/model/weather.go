@@ -1,5 +1,14 @@
 package model
 
+// Weather holds the current conditions in normalized units: Celsius, percent,
+// km/h and degrees. Temperature is the only field every provider must answer,
+// the others are nil when the provider did not return them.
 type Weather struct {
-	Temperature float64
+	Temperature         float64
+	ApparentTemperature *float64
+	Humidity            *float64
+	WindSpeed           *float64
+	WindDirection       *float64
+	WeatherCode         *int
+	Provider            string
 }
@@ -4,66 +4,82 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
 	"go.opentelemetry.io/otel"
 )
 
 type AddressRepository interface {
-	GetAddress(string, context.Context, context.Context) (*model.Address, error)
+	GetAddress(string, context.Context) (*model.Address, error)
 }
 
 type addressRepository struct {
-	URL string
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
 }
 
-func NewAddressRepository(url string) AddressRepository {
+func NewAddressRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) AddressRepository {
 	return &addressRepository{
-		URL: url,
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
 	}
 }
 
-func (r *addressRepository) GetAddress(cep string, ctx context.Context, ctxDistributed context.Context) (*model.Address, error) {
+func (r *addressRepository) GetAddress(zipcode string, ctx context.Context) (_ *model.Address, err error) {
 	tracer := otel.Tracer("AddressRepository")
 
-	_, span := tracer.Start(ctx, "AddressRepository.GetAddress")
-	defer span.End()
+	ctx, span := tracer.Start(ctx, "AddressRepository.GetAddress")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("ViaCEP", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "ViaCEP request failed", &err, ctx, "cep", zipcode)
 
-	_, spanDistributed := tracer.Start(ctxDistributed, "AddressRepository.GetAddress")
-	defer spanDistributed.End()
+	parsed, err := cep.Parse(zipcode)
+	if err != nil {
+		return nil, err
+	}
 
-	if cep == "" || len(cep) != 8 || !utils.IsNumber(cep) {
-		return nil, fmt.Errorf("invalid zipcode")
+	addressURL, err := utils.BuildURL(r.baseURL, []string{"ws", parsed.String(), "json", ""}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when building ViaCEP url: %w", err)
 	}
 
-	var url string
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	if os.Getenv("TEST") == "true" {
-		url = r.URL
-	} else {
-		url = fmt.Sprintf(r.URL, cep)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addressURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
 	}
 
-	resp, err := http.Get(url)
+	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for zipcode %s information: %w", cep, err)
+		return nil, apperrors.Transport("ViaCEP", err, "error when searching for zipcode %s information", parsed)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ViaCEP api returned status %d for zipcode %s: %w", resp.StatusCode, cep, err)
+		return nil, apperrors.Status("ViaCEP", resp.StatusCode, "ViaCEP api returned status %d for zipcode %s", resp.StatusCode, parsed)
 	}
 
 	var address model.Address
 	if err := json.NewDecoder(resp.Body).Decode(&address); err != nil {
-		return nil, fmt.Errorf("error when decoding ViaCEP api response to zipcode %s: %w", cep, err)
+		return nil, apperrors.BadPayload("ViaCEP", err, "error when decoding ViaCEP api response to zipcode %s", parsed)
 	}
 
 	if address.PostalCode == "" {
-		return nil, fmt.Errorf("can not find zipcode")
+		return nil, apperrors.ErrCEPNotFound
 	}
 
 	return &address, nil
@@ -2,28 +2,34 @@ package repository_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 func TestAddressRepository_Success(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/12345678/json/" {
+			t.Errorf("Path mismatch: expected %v, got %v", "/ws/12345678/json/", r.URL.Path)
+		}
+
 		responseBody := `{"cep":"12345-678","logradouro":"Rua Exemplo","complemento":"","bairro":"Bairro","localidade":"Cidade","uf":"Estado"}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewAddressRepository(server.URL)
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
-	address, err := repo.GetAddress("12345678", context.Background(), context.Background())
+	address, err := repo.GetAddress("12345678", context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -63,19 +69,17 @@ func TestAddressRepository_Success(t *testing.T) {
 }
 
 func TestAddressRepository_InvalidCep(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `{}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewAddressRepository(server.URL)
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	cep := "0"
 
-	_, err := repo.GetAddress(cep, context.Background(), context.Background())
+	_, err := repo.GetAddress(cep, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -87,19 +91,17 @@ func TestAddressRepository_InvalidCep(t *testing.T) {
 }
 
 func TestAddressRepository_NotFindZipcode(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `{"erro":true}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewAddressRepository(server.URL)
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	cep := "99999999"
 
-	_, err := repo.GetAddress(cep, context.Background(), context.Background())
+	_, err := repo.GetAddress(cep, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -111,18 +113,16 @@ func TestAddressRepository_NotFindZipcode(t *testing.T) {
 }
 
 func TestAddressRepository_ErrorHttp(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "", http.StatusMovedPermanently)
 	}))
 	defer server.Close()
 
-	repo := repository.NewAddressRepository(server.URL)
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	cep := "12345678"
 
-	_, err := repo.GetAddress(cep, context.Background(), context.Background())
+	_, err := repo.GetAddress(cep, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -134,8 +134,6 @@ func TestAddressRepository_ErrorHttp(t *testing.T) {
 }
 
 func TestAddressRepository_NotStatusOK(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	statusServerError := http.StatusInternalServerError
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -143,11 +141,11 @@ func TestAddressRepository_NotStatusOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewAddressRepository(server.URL)
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	cep := "12345678"
 
-	_, err := repo.GetAddress(cep, context.Background(), context.Background())
+	_, err := repo.GetAddress(cep, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -159,19 +157,17 @@ func TestAddressRepository_NotStatusOK(t *testing.T) {
 }
 
 func TestAddressRepository_ErrorJsonDecoder(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `error`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewAddressRepository(server.URL)
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	cep := "12345678"
 
-	_, err := repo.GetAddress(cep, context.Background(), context.Background())
+	_, err := repo.GetAddress(cep, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -181,3 +177,94 @@ func TestAddressRepository_ErrorJsonDecoder(t *testing.T) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
 }
+
+func TestAddressRepository_ErrorKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		cep     string
+		handler http.HandlerFunc
+		want    error
+	}{
+		{"invalid", "0", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) }, apperrors.ErrInvalidCEP},
+		{"not found", "99999999", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{"erro":true}`)) }, apperrors.ErrCEPNotFound},
+		{"unavailable", "12345678", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) }, apperrors.ErrUpstreamUnavailable},
+		{"bad payload", "12345678", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`error`)) }, apperrors.ErrUpstreamBadPayload},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(test.handler)
+
+		repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+		_, err := repo.GetAddress(test.cep, context.Background())
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, err)
+		}
+
+		server.Close()
+	}
+}
+
+func TestAddressRepository_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	repo := repository.NewAddressRepository(server.URL, server.Client(), 10*time.Millisecond, logging.Discard())
+
+	_, err := repo.GetAddress("12345678", context.Background())
+	if !errors.Is(err, apperrors.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestAddressRepository_CancelledContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cep":"12345-678"}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetAddress("12345678", ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestAddressRepository_FormattedCEP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/01001000/json/" {
+			t.Errorf("Path mismatch: expected %v, got %v", "/ws/01001000/json/", r.URL.Path)
+		}
+
+		w.Write([]byte(`{"cep":"01001-000","localidade":"São Paulo","uf":"SP"}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	if _, err := repo.GetAddress("01001-000", context.Background()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestAddressRepository_OutOfRangeCEP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected ViaCEP not to be called")
+	}))
+	defer server.Close()
+
+	repo := repository.NewAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	if _, err := repo.GetAddress("00999-999", context.Background()); !errors.Is(err, apperrors.ErrCEPNotFound) {
+		t.Errorf("Expected ErrCEPNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+)
+
+// AwesomeAPI also answers the coordinates and the area code of the zipcode,
+// which are left out as the coordinates come from Nominatim.
+type awesomeAPIAddress struct {
+	CEP      string `json:"cep"`
+	Address  string `json:"address"`
+	District string `json:"district"`
+	City     string `json:"city"`
+	State    string `json:"state"`
+}
+
+func NewAwesomeAPIAddressRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) AddressRepository {
+	return &jsonAddressRepository[awesomeAPIAddress]{
+		upstream: "AwesomeAPI",
+		baseURL:  baseURL,
+		path: func(zipcode cep.CEP) []string {
+			return []string{"json", zipcode.String()}
+		},
+		address: func(address awesomeAPIAddress) *model.Address {
+			if address.CEP == "" {
+				return nil
+			}
+
+			return &model.Address{
+				Street:   address.Address,
+				District: address.District,
+				City:     address.City,
+				State:    address.State,
+			}
+		},
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+)
+
+type brasilAPIAddress struct {
+	CEP          string `json:"cep"`
+	State        string `json:"state"`
+	City         string `json:"city"`
+	Neighborhood string `json:"neighborhood"`
+	Street       string `json:"street"`
+}
+
+// NewBrasilAPIAddressRepository finds addresses through the CEP API of
+// BrasilAPI, which has no complement.
+func NewBrasilAPIAddressRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) AddressRepository {
+	return &jsonAddressRepository[brasilAPIAddress]{
+		upstream: "BrasilAPI",
+		baseURL:  baseURL,
+		path: func(zipcode cep.CEP) []string {
+			return []string{"api", "cep", "v1", zipcode.String()}
+		},
+		address: func(address brasilAPIAddress) *model.Address {
+			if address.CEP == "" {
+				return nil
+			}
+
+			return &model.Address{
+				Street:   address.Street,
+				District: address.Neighborhood,
+				City:     address.City,
+				State:    address.State,
+			}
+		},
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
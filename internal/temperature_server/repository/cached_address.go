@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedAddressRepository struct {
+	next  AddressRepository
+	cache *cache.Cache[string, model.Address]
+}
+
+func NewCachedAddressRepository(next AddressRepository, cache *cache.Cache[string, model.Address]) AddressRepository {
+	return &cachedAddressRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+func (r *cachedAddressRepository) GetAddress(cep string, ctx context.Context) (*model.Address, error) {
+	tracer := otel.Tracer("CachedAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedAddressRepository.GetAddress")
+	defer span.End()
+
+	if address, ok := r.cache.Get(cep); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("address", true, ctx)
+
+		return &address, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("address", false, ctx)
+
+	address, err := r.next.GetAddress(cep, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(cep, *address)
+
+	return address, nil
+}
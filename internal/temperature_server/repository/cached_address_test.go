@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+)
+
+type CountingAddressRepository struct {
+	Address *model.Address
+	Err     error
+	Calls   int
+}
+
+func (m *CountingAddressRepository) GetAddress(string, context.Context) (*model.Address, error) {
+	m.Calls++
+	return m.Address, m.Err
+}
+
+func TestCachedAddressRepository_Hit(t *testing.T) {
+	next := &CountingAddressRepository{Address: &model.Address{PostalCode: "12345-678", City: "Cidade", State: "Estado"}}
+
+	repo := repository.NewCachedAddressRepository(next, cache.New[string, model.Address](10, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		address, err := repo.GetAddress("12345678", context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if address.City != "Cidade" {
+			t.Errorf("City mismatch: expected %v, got %v", "Cidade", address.City)
+		}
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+}
+
+func TestCachedAddressRepository_ErrorNotCached(t *testing.T) {
+	next := &CountingAddressRepository{Err: apperrors.ErrCEPNotFound}
+
+	repo := repository.NewCachedAddressRepository(next, cache.New[string, model.Address](10, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetAddress("12345678", context.Background())
+		if !errors.Is(err, apperrors.ErrCEPNotFound) {
+			t.Errorf("Expected ErrCEPNotFound, got %v", err)
+		}
+	}
+
+	if next.Calls != 2 {
+		t.Errorf("Expected 2 calls to the wrapped repository, got %d", next.Calls)
+	}
+}
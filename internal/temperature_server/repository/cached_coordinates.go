@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedCoordinatesRepository struct {
+	next  CoordinatesRepository
+	cache *cache.Cache[string, model.Coordinates]
+}
+
+func NewCachedCoordinatesRepository(next CoordinatesRepository, cache *cache.Cache[string, model.Coordinates]) CoordinatesRepository {
+	return &cachedCoordinatesRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+func (r *cachedCoordinatesRepository) GetCoordinates(address *model.Address, ctx context.Context) (*model.Coordinates, error) {
+	tracer := otel.Tracer("CachedCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedCoordinatesRepository.GetCoordinates")
+	defer span.End()
+
+	key := addressCacheKey(address)
+
+	if coordinates, ok := r.cache.Get(key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("coordinates", true, ctx)
+
+		return &coordinates, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("coordinates", false, ctx)
+
+	coordinates, err := r.next.GetCoordinates(address, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, *coordinates)
+
+	return coordinates, nil
+}
+
+// addressCacheKey identifies an address by the fields the geocoding and the
+// weather by address lookups actually use.
+func addressCacheKey(address *model.Address) string {
+	return address.City + "|" + address.State
+}
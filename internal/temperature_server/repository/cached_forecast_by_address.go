@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedForecastByAddressRepository struct {
+	next  ForecastByAddressRepository
+	cache *cache.Cache[string, []model.ForecastPoint]
+}
+
+func NewCachedForecastByAddressRepository(next ForecastByAddressRepository, cache *cache.Cache[string, []model.ForecastPoint]) ForecastByAddressRepository {
+	return &cachedForecastByAddressRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+func (r *cachedForecastByAddressRepository) GetForecast(address *model.Address, query model.ForecastQuery, ctx context.Context) ([]model.ForecastPoint, error) {
+	tracer := otel.Tracer("CachedForecastByAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedForecastByAddressRepository.GetForecast")
+	defer span.End()
+
+	key := fmt.Sprintf("%s|%d|%s", addressCacheKey(address), query.Days, query.Granularity)
+
+	if points, ok := r.cache.Get(key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("forecast_by_address", true, ctx)
+
+		return points, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("forecast_by_address", false, ctx)
+
+	points, err := r.next.GetForecast(address, query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, points)
+
+	return points, nil
+}
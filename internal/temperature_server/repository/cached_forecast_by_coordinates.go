@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedForecastByCoordinatesRepository struct {
+	next  ForecastByCoordinatesRepository
+	cache *cache.Cache[string, []model.ForecastPoint]
+}
+
+func NewCachedForecastByCoordinatesRepository(next ForecastByCoordinatesRepository, cache *cache.Cache[string, []model.ForecastPoint]) ForecastByCoordinatesRepository {
+	return &cachedForecastByCoordinatesRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+func (r *cachedForecastByCoordinatesRepository) GetForecast(coordinates *model.Coordinates, query model.ForecastQuery, ctx context.Context) ([]model.ForecastPoint, error) {
+	tracer := otel.Tracer("CachedForecastByCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedForecastByCoordinatesRepository.GetForecast")
+	defer span.End()
+
+	key := fmt.Sprintf("%s,%s|%d|%s", coordinates.Latitude, coordinates.Longitude, query.Days, query.Granularity)
+
+	if points, ok := r.cache.Get(key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("forecast_by_coordinates", true, ctx)
+
+		return points, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("forecast_by_coordinates", false, ctx)
+
+	points, err := r.next.GetForecast(coordinates, query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, points)
+
+	return points, nil
+}
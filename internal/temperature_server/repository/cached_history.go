@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedHistoryRepository struct {
+	next  HistoryRepository
+	cache *cache.Cache[string, []model.HistoryDay]
+}
+
+func NewCachedHistoryRepository(next HistoryRepository, cache *cache.Cache[string, []model.HistoryDay]) HistoryRepository {
+	return &cachedHistoryRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+// GetHistory only caches complete answers. The archive fills the most recent
+// days with a delay, and once filled a past day no longer changes.
+func (r *cachedHistoryRepository) GetHistory(coordinates *model.Coordinates, query model.HistoryQuery, ctx context.Context) ([]model.HistoryDay, error) {
+	tracer := otel.Tracer("CachedHistoryRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedHistoryRepository.GetHistory")
+	defer span.End()
+
+	key := fmt.Sprintf("%s,%s|%s|%s", coordinates.Latitude, coordinates.Longitude, query.Start.Format(model.DateLayout), query.End.Format(model.DateLayout))
+
+	if days, ok := r.cache.Get(key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("history", true, ctx)
+
+		return days, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("history", false, ctx)
+
+	days, err := r.next.GetHistory(coordinates, query, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, day := range days {
+		if !day.Complete() {
+			return days, nil
+		}
+	}
+
+	r.cache.Set(key, days)
+
+	return days, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedWeatherByAddressRepository struct {
+	next  WeatherByAddressRepository
+	cache *cache.Cache[string, model.Weather]
+}
+
+func NewCachedWeatherByAddressRepository(next WeatherByAddressRepository, cache *cache.Cache[string, model.Weather]) WeatherByAddressRepository {
+	return &cachedWeatherByAddressRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+func (r *cachedWeatherByAddressRepository) GetWeather(address *model.Address, ctx context.Context) (*model.Weather, error) {
+	tracer := otel.Tracer("CachedWeatherByAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedWeatherByAddressRepository.GetWeather")
+	defer span.End()
+
+	key := addressCacheKey(address)
+
+	if weather, ok := r.cache.Get(key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("weather_by_address", true, ctx)
+
+		return &weather, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("weather_by_address", false, ctx)
+
+	weather, err := r.next.GetWeather(address, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, *weather)
+
+	return weather, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type cachedWeatherByCoordinatesRepository struct {
+	next  WeatherByCoordinatesRepository
+	cache *cache.Cache[string, model.Weather]
+}
+
+func NewCachedWeatherByCoordinatesRepository(next WeatherByCoordinatesRepository, cache *cache.Cache[string, model.Weather]) WeatherByCoordinatesRepository {
+	return &cachedWeatherByCoordinatesRepository{
+		next:  next,
+		cache: cache,
+	}
+}
+
+func (r *cachedWeatherByCoordinatesRepository) GetWeather(coordinates *model.Coordinates, ctx context.Context) (*model.Weather, error) {
+	tracer := otel.Tracer("CachedWeatherByCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CachedWeatherByCoordinatesRepository.GetWeather")
+	defer span.End()
+
+	key := coordinates.Latitude + "," + coordinates.Longitude
+
+	if weather, ok := r.cache.Get(key); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		telemetry.RecordCacheLookup("weather_by_coordinates", true, ctx)
+
+		return &weather, nil
+	}
+
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	telemetry.RecordCacheLookup("weather_by_coordinates", false, ctx)
+
+	weather, err := r.next.GetWeather(coordinates, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, *weather)
+
+	return weather, nil
+}
@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+)
+
+type CountingWeatherByCoordinatesRepository struct {
+	Weather *model.Weather
+	Err     error
+	Calls   int
+}
+
+func (m *CountingWeatherByCoordinatesRepository) GetWeather(*model.Coordinates, context.Context) (*model.Weather, error) {
+	m.Calls++
+	return m.Weather, m.Err
+}
+
+func TestCachedWeatherByCoordinatesRepository_KeyedByCoordinates(t *testing.T) {
+	next := &CountingWeatherByCoordinatesRepository{Weather: &model.Weather{Temperature: 30}}
+
+	repo := repository.NewCachedWeatherByCoordinatesRepository(next, cache.New[string, model.Weather](10, time.Minute))
+
+	coordinates := []*model.Coordinates{
+		{Latitude: "123", Longitude: "321"},
+		{Latitude: "123", Longitude: "321"},
+		{Latitude: "456", Longitude: "654"},
+	}
+
+	for _, c := range coordinates {
+		weather, err := repo.GetWeather(c, context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if weather.Temperature != 30 {
+			t.Errorf("Temperature mismatch: expected %v, got %v", 30, weather.Temperature)
+		}
+	}
+
+	if next.Calls != 2 {
+		t.Errorf("Expected 2 calls to the wrapped repository, got %d", next.Calls)
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerAddressRepository struct {
+	next           AddressRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerAddressRepository(next AddressRepository, circuitBreaker *circuitbreaker.CircuitBreaker) AddressRepository {
+	return &circuitBreakerAddressRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerAddressRepository) GetAddress(cep string, ctx context.Context) (*model.Address, error) {
+	tracer := otel.Tracer("CircuitBreakerAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerAddressRepository.GetAddress")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	address, err := r.next.GetAddress(cep, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return address, err
+}
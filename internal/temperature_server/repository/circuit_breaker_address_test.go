@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+func TestCircuitBreakerAddressRepository_FailFastWhenOpen(t *testing.T) {
+	next := &CountingAddressRepository{Err: apperrors.Status("ViaCEP", http.StatusInternalServerError, "ViaCEP api returned status 500")}
+
+	breaker := circuitbreaker.New("ViaCEP", circuitbreaker.Settings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	repo := repository.NewCircuitBreakerAddressRepository(next, breaker)
+
+	for i := 0; i < 3; i++ {
+		repo.GetAddress("12345678", context.Background())
+	}
+
+	if next.Calls != 2 {
+		t.Errorf("Expected 2 calls to the wrapped repository, got %d", next.Calls)
+	}
+
+	_, err := repo.GetAddress("12345678", context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestCircuitBreakerAddressRepository_NotFoundKeepsClosed(t *testing.T) {
+	next := &CountingAddressRepository{Err: apperrors.ErrCEPNotFound}
+
+	breaker := circuitbreaker.New("ViaCEP", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	repo := repository.NewCircuitBreakerAddressRepository(next, breaker)
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.GetAddress("99999999", context.Background())
+		if !errors.Is(err, apperrors.ErrCEPNotFound) {
+			t.Errorf("Expected ErrCEPNotFound, got %v", err)
+		}
+	}
+
+	if next.Calls != 3 {
+		t.Errorf("Expected 3 calls to the wrapped repository, got %d", next.Calls)
+	}
+}
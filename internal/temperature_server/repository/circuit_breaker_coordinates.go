@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerCoordinatesRepository struct {
+	next           CoordinatesRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerCoordinatesRepository(next CoordinatesRepository, circuitBreaker *circuitbreaker.CircuitBreaker) CoordinatesRepository {
+	return &circuitBreakerCoordinatesRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerCoordinatesRepository) GetCoordinates(address *model.Address, ctx context.Context) (*model.Coordinates, error) {
+	tracer := otel.Tracer("CircuitBreakerCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerCoordinatesRepository.GetCoordinates")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	coordinates, err := r.next.GetCoordinates(address, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return coordinates, err
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/httpclient"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type CountingCoordinatesRepository struct {
+	Coordinates *model.Coordinates
+	Err         error
+	Calls       int
+}
+
+func (m *CountingCoordinatesRepository) GetCoordinates(*model.Address, context.Context) (*model.Coordinates, error) {
+	m.Calls++
+	return m.Coordinates, m.Err
+}
+
+func TestCircuitBreakerCoordinatesRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingCoordinatesRepository{Err: apperrors.Status("Nominatim", http.StatusInternalServerError, "nominatim api returned status 500")}
+
+	breaker := circuitbreaker.New("Nominatim", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerCoordinatesRepository(next, breaker)
+
+	address := &model.Address{City: "Cidade", State: "Estado"}
+
+	repo.GetCoordinates(address, context.Background())
+
+	_, err := repo.GetCoordinates(address, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetCoordinates(address, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
+
+func TestCircuitBreakerCoordinatesRepository_RateLimitKeepsClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"lat":"123","lon":"321"}]`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	client := httpclient.New(httpclient.Settings{
+		Limiters: httpclient.NewLimiters(map[string]float64{serverURL.Host: 1}),
+	})
+
+	breaker := circuitbreaker.New("Nominatim", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerCoordinatesRepository(
+		repository.NewCoordinatesRepository(server.URL, client, 50*time.Millisecond, logging.Discard()),
+		breaker,
+	)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		limited int
+	)
+
+	for range 12 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := repo.GetCoordinates(&model.Address{City: "Cidade", State: "Estado"}, context.Background())
+			if errors.Is(err, apperrors.ErrRateLimited) {
+				mu.Lock()
+				limited++
+				mu.Unlock()
+			} else if err != nil {
+				t.Errorf("Expected no error or ErrRateLimited, got %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if limited == 0 {
+		t.Error("Expected the limiter to refuse some calls")
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to stay closed, got %s", state)
+	}
+}
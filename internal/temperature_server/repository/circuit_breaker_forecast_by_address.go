@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerForecastByAddressRepository struct {
+	next           ForecastByAddressRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerForecastByAddressRepository(next ForecastByAddressRepository, circuitBreaker *circuitbreaker.CircuitBreaker) ForecastByAddressRepository {
+	return &circuitBreakerForecastByAddressRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerForecastByAddressRepository) GetForecast(address *model.Address, query model.ForecastQuery, ctx context.Context) ([]model.ForecastPoint, error) {
+	tracer := otel.Tracer("CircuitBreakerForecastByAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerForecastByAddressRepository.GetForecast")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	points, err := r.next.GetForecast(address, query, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return points, err
+}
@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+type CountingForecastByAddressRepository struct {
+	Points []model.ForecastPoint
+	Err    error
+	Calls  int
+}
+
+func (m *CountingForecastByAddressRepository) GetForecast(*model.Address, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error) {
+	m.Calls++
+	return m.Points, m.Err
+}
+
+func TestCircuitBreakerForecastByAddressRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingForecastByAddressRepository{Err: apperrors.Status("wttr.in", http.StatusInternalServerError, "wttr.in api returned status 500")}
+
+	breaker := circuitbreaker.New("wttr.in", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerForecastByAddressRepository(next, breaker)
+
+	address := &model.Address{City: "Cidade", State: "Estado"}
+	query := model.ForecastQuery{Days: 1, Granularity: model.GranularityDaily}
+
+	repo.GetForecast(address, query, context.Background())
+
+	_, err := repo.GetForecast(address, query, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetForecast(address, query, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
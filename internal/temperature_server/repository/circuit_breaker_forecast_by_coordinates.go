@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerForecastByCoordinatesRepository struct {
+	next           ForecastByCoordinatesRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerForecastByCoordinatesRepository(next ForecastByCoordinatesRepository, circuitBreaker *circuitbreaker.CircuitBreaker) ForecastByCoordinatesRepository {
+	return &circuitBreakerForecastByCoordinatesRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerForecastByCoordinatesRepository) GetForecast(coordinates *model.Coordinates, query model.ForecastQuery, ctx context.Context) ([]model.ForecastPoint, error) {
+	tracer := otel.Tracer("CircuitBreakerForecastByCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerForecastByCoordinatesRepository.GetForecast")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	points, err := r.next.GetForecast(coordinates, query, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return points, err
+}
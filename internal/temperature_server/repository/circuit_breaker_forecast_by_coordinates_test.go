@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+type CountingForecastByCoordinatesRepository struct {
+	Points []model.ForecastPoint
+	Err    error
+	Calls  int
+}
+
+func (m *CountingForecastByCoordinatesRepository) GetForecast(*model.Coordinates, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error) {
+	m.Calls++
+	return m.Points, m.Err
+}
+
+func TestCircuitBreakerForecastByCoordinatesRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingForecastByCoordinatesRepository{Err: apperrors.Status("Open-Meteo", http.StatusInternalServerError, "open-meteo api returned status 500")}
+
+	breaker := circuitbreaker.New("Open-Meteo", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerForecastByCoordinatesRepository(next, breaker)
+
+	coordinates := &model.Coordinates{Latitude: "123", Longitude: "321"}
+	query := model.ForecastQuery{Days: 1, Granularity: model.GranularityHourly}
+
+	repo.GetForecast(coordinates, query, context.Background())
+
+	_, err := repo.GetForecast(coordinates, query, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetForecast(coordinates, query, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
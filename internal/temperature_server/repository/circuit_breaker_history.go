@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerHistoryRepository struct {
+	next           HistoryRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerHistoryRepository(next HistoryRepository, circuitBreaker *circuitbreaker.CircuitBreaker) HistoryRepository {
+	return &circuitBreakerHistoryRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerHistoryRepository) GetHistory(coordinates *model.Coordinates, query model.HistoryQuery, ctx context.Context) ([]model.HistoryDay, error) {
+	tracer := otel.Tracer("CircuitBreakerHistoryRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerHistoryRepository.GetHistory")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	days, err := r.next.GetHistory(coordinates, query, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return days, err
+}
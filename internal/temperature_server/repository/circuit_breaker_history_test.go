@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+func TestCircuitBreakerHistoryRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingHistoryRepository{Err: apperrors.Status("Open-Meteo archive", http.StatusInternalServerError, "open-meteo archive api returned status 500")}
+
+	breaker := circuitbreaker.New("Open-Meteo archive", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerHistoryRepository(next, breaker)
+
+	coordinates := &model.Coordinates{Latitude: "123", Longitude: "321"}
+	query := model.HistoryQuery{Start: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)}
+
+	repo.GetHistory(coordinates, query, context.Background())
+
+	_, err := repo.GetHistory(coordinates, query, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetHistory(coordinates, query, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerWeatherByAddressRepository struct {
+	next           WeatherByAddressRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerWeatherByAddressRepository(next WeatherByAddressRepository, circuitBreaker *circuitbreaker.CircuitBreaker) WeatherByAddressRepository {
+	return &circuitBreakerWeatherByAddressRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerWeatherByAddressRepository) GetWeather(address *model.Address, ctx context.Context) (*model.Weather, error) {
+	tracer := otel.Tracer("CircuitBreakerWeatherByAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerWeatherByAddressRepository.GetWeather")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	weather, err := r.next.GetWeather(address, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return weather, err
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+type CountingWeatherByAddressRepository struct {
+	Weather *model.Weather
+	Err     error
+	Calls   int
+}
+
+func (m *CountingWeatherByAddressRepository) GetWeather(*model.Address, context.Context) (*model.Weather, error) {
+	m.Calls++
+	return m.Weather, m.Err
+}
+
+func TestCircuitBreakerWeatherByAddressRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingWeatherByAddressRepository{Err: apperrors.Status("wttr.in", http.StatusInternalServerError, "wttr.in api returned status 500")}
+
+	breaker := circuitbreaker.New("wttr.in", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerWeatherByAddressRepository(next, breaker)
+
+	address := &model.Address{City: "Cidade", State: "Estado"}
+
+	repo.GetWeather(address, context.Background())
+
+	_, err := repo.GetWeather(address, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetWeather(address, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type circuitBreakerWeatherByCoordinatesRepository struct {
+	next           WeatherByCoordinatesRepository
+	circuitBreaker *circuitbreaker.CircuitBreaker
+}
+
+func NewCircuitBreakerWeatherByCoordinatesRepository(next WeatherByCoordinatesRepository, circuitBreaker *circuitbreaker.CircuitBreaker) WeatherByCoordinatesRepository {
+	return &circuitBreakerWeatherByCoordinatesRepository{
+		next:           next,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (r *circuitBreakerWeatherByCoordinatesRepository) GetWeather(coordinates *model.Coordinates, ctx context.Context) (*model.Weather, error) {
+	tracer := otel.Tracer("CircuitBreakerWeatherByCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CircuitBreakerWeatherByCoordinatesRepository.GetWeather")
+	defer span.End()
+
+	stateAttribute := attribute.String("circuit_breaker.state", r.circuitBreaker.State().String())
+	span.SetAttributes(stateAttribute)
+
+	if err := r.circuitBreaker.Allow(); err != nil {
+		telemetry.RecordError(span, err)
+
+		return nil, err
+	}
+
+	weather, err := r.next.GetWeather(coordinates, ctx)
+
+	r.circuitBreaker.Done(err)
+
+	return weather, err
+}
@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+func TestCircuitBreakerWeatherByCoordinatesRepository_OpensAndRecovers(t *testing.T) {
+	next := &CountingWeatherByCoordinatesRepository{Err: apperrors.Status("Open-Meteo", http.StatusInternalServerError, "open-meteo api returned status 500")}
+
+	breaker := circuitbreaker.New("Open-Meteo", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	repo := repository.NewCircuitBreakerWeatherByCoordinatesRepository(next, breaker)
+
+	coordinates := &model.Coordinates{Latitude: "123", Longitude: "321"}
+
+	repo.GetWeather(coordinates, context.Background())
+
+	_, err := repo.GetWeather(coordinates, context.Background())
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	if next.Calls != 1 {
+		t.Errorf("Expected 1 call to the wrapped repository, got %d", next.Calls)
+	}
+
+	next.Err = nil
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := repo.GetWeather(coordinates, context.Background()); err != nil {
+		t.Errorf("Expected the trial request to go through, got %v", err)
+	}
+
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Errorf("Expected the breaker to close again, got %s", state)
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/coalesce"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// coalescedWeatherByCoordinatesRepository shares a weather call already in
+// flight for the same coordinates, as zipcodes of the same city are geocoded
+// to the same point.
+type coalescedWeatherByCoordinatesRepository struct {
+	next     WeatherByCoordinatesRepository
+	inFlight *coalesce.Group[string, *model.Weather]
+}
+
+func NewCoalescedWeatherByCoordinatesRepository(next WeatherByCoordinatesRepository) WeatherByCoordinatesRepository {
+	return &coalescedWeatherByCoordinatesRepository{
+		next:     next,
+		inFlight: coalesce.New[string, *model.Weather](),
+	}
+}
+
+func (r *coalescedWeatherByCoordinatesRepository) GetWeather(coordinates *model.Coordinates, ctx context.Context) (_ *model.Weather, err error) {
+	tracer := otel.Tracer("CoalescedWeatherByCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "CoalescedWeatherByCoordinatesRepository.GetWeather")
+	defer telemetry.EndSpan(span, &err)
+
+	key := coordinates.Latitude + "," + coordinates.Longitude
+
+	weather, leader, err := r.inFlight.Do(key, func(ctx context.Context) (*model.Weather, error) {
+		return r.next.GetWeather(coordinates, ctx)
+	}, ctx)
+
+	if leader.IsValid() {
+		span.AddLink(trace.Link{SpanContext: leader})
+	}
+
+	span.SetAttributes(attribute.Bool("coalesce.shared", leader.IsValid()))
+	telemetry.RecordCoalescedCall("weather_by_coordinates", leader.IsValid(), ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Every caller gets its own copy, as the service sets the provider on it.
+	shared := *weather
+
+	return &shared, nil
+}
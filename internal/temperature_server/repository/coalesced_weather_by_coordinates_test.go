@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+)
+
+type BlockingWeatherByCoordinatesRepository struct {
+	Release chan struct{}
+	Calls   atomic.Int32
+}
+
+func (m *BlockingWeatherByCoordinatesRepository) GetWeather(*model.Coordinates, context.Context) (*model.Weather, error) {
+	m.Calls.Add(1)
+	<-m.Release
+	return &model.Weather{Temperature: 30}, nil
+}
+
+func TestCoalescedWeatherByCoordinatesRepository_SharesInFlightCalls(t *testing.T) {
+	next := &BlockingWeatherByCoordinatesRepository{Release: make(chan struct{})}
+
+	repo := repository.NewCoalescedWeatherByCoordinatesRepository(next)
+
+	coordinates := []*model.Coordinates{
+		{Latitude: "123", Longitude: "321"},
+		{Latitude: "123", Longitude: "321"},
+		{Latitude: "123", Longitude: "321"},
+		{Latitude: "456", Longitude: "654"},
+	}
+
+	weathers := make([]*model.Weather, len(coordinates))
+
+	var wg sync.WaitGroup
+
+	for i, c := range coordinates {
+		wg.Add(1)
+
+		go func(i int, c *model.Coordinates) {
+			defer wg.Done()
+
+			weather, err := repo.GetWeather(c, context.Background())
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+
+			weathers[i] = weather
+		}(i, c)
+	}
+
+	// Gives every call the time to join the one in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(next.Release)
+	wg.Wait()
+
+	if calls := next.Calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 calls to the wrapped repository, got %d", calls)
+	}
+
+	if weathers[0] == weathers[1] {
+		t.Error("Expected every caller to get its own copy of the weather")
+	}
+}
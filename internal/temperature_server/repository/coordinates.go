@@ -4,38 +4,46 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
 	"go.opentelemetry.io/otel"
 )
 
 type CoordinatesRepository interface {
-	GetCoordinates(*model.Address, context.Context, context.Context) (*model.Coordinates, error)
+	GetCoordinates(*model.Address, context.Context) (*model.Coordinates, error)
 }
 
 type coordinatesRepository struct {
-	URL        string
-	BaseMethod string
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
 }
 
-func NewCoordinatesRepository(url string) CoordinatesRepository {
+func NewCoordinatesRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) CoordinatesRepository {
 	return &coordinatesRepository{
-		URL: url,
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
 	}
 }
 
-func (r *coordinatesRepository) GetCoordinates(address *model.Address, ctx context.Context, ctxDistributed context.Context) (*model.Coordinates, error) {
+func (r *coordinatesRepository) GetCoordinates(address *model.Address, ctx context.Context) (_ *model.Coordinates, err error) {
 	tracer := otel.Tracer("CoordinatesRepository")
 
-	_, span := tracer.Start(ctx, "CoordinatesRepository.GetCoordinates")
-	defer span.End()
-
-	_, spanDistributed := tracer.Start(ctxDistributed, "CoordinatesRepository.GetCoordinates")
-	defer spanDistributed.End()
-
-	baseURL := r.URL
+	ctx, span := tracer.Start(ctx, "CoordinatesRepository.GetCoordinates")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("Nominatim", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "Nominatim request failed", &err, ctx, "city", address.City, "state", address.State)
 
 	params := url.Values{}
 	params.Add("city", address.City)
@@ -43,21 +51,28 @@ func (r *coordinatesRepository) GetCoordinates(address *model.Address, ctx conte
 	params.Add("country", "Brasil")
 	params.Add("format", "json")
 
-	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	searchURL, err := utils.BuildURL(r.baseURL, []string{"search"}, params)
+	if err != nil {
+		return nil, fmt.Errorf("error when building coordinates api url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error when creating request: %w", err)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for coordinates for the address: %w", err)
+		return nil, apperrors.Transport("Nominatim", err, "error when searching for coordinates for the address")
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("coordinates api returned status %d: %w", resp.StatusCode, err)
+		return nil, apperrors.Status("Nominatim", resp.StatusCode, "coordinates api returned status %d", resp.StatusCode)
 	}
 
 	var results []struct {
@@ -66,11 +81,13 @@ func (r *coordinatesRepository) GetCoordinates(address *model.Address, ctx conte
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return nil, fmt.Errorf("error decoding coordinates api response: %w", err)
+		return nil, apperrors.BadPayload("Nominatim", err, "error decoding coordinates api response")
 	}
 
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no coordinates found for the address")
+		// Nominatim answered, it just does not know the address, which must
+		// not count against its circuit breaker.
+		return nil, fmt.Errorf("%w: no coordinates found for the address", apperrors.ErrLocationNotFound)
 	}
 
 	coordinates := &model.Coordinates{
@@ -2,24 +2,48 @@ package repository_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 func TestCoordinatesRepository_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			t.Errorf("Path mismatch: expected %v, got %v", "/search", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("city") != "Cidade" {
+			t.Errorf("Query city mismatch: expected %v, got %v", "Cidade", r.URL.Query().Get("city"))
+		}
+
+		if r.URL.Query().Get("state") != "Estado" {
+			t.Errorf("Query state mismatch: expected %v, got %v", "Estado", r.URL.Query().Get("state"))
+		}
+
+		if r.URL.Query().Get("country") != "Brasil" {
+			t.Errorf("Query country mismatch: expected %v, got %v", "Brasil", r.URL.Query().Get("country"))
+		}
+
+		if r.URL.Query().Get("format") != "json" {
+			t.Errorf("Query format mismatch: expected %v, got %v", "json", r.URL.Query().Get("format"))
+		}
+
 		responseBody := `[{"lat":"123","lon":"321"}]`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewCoordinatesRepository(server.URL)
+	repo := repository.NewCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -30,7 +54,7 @@ func TestCoordinatesRepository_Success(t *testing.T) {
 		State:      "Estado",
 	}
 
-	coordinates, err := repo.GetCoordinates(address, context.Background(), context.Background())
+	coordinates, err := repo.GetCoordinates(address, context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -55,7 +79,7 @@ func TestCoordinatesRepository_ErrorHttp(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewCoordinatesRepository(server.URL)
+	repo := repository.NewCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -66,7 +90,7 @@ func TestCoordinatesRepository_ErrorHttp(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetCoordinates(address, context.Background(), context.Background())
+	_, err := repo.GetCoordinates(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -85,7 +109,7 @@ func TestCoordinatesRepository_NotStatusOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewCoordinatesRepository(server.URL)
+	repo := repository.NewCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -96,7 +120,7 @@ func TestCoordinatesRepository_NotStatusOK(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetCoordinates(address, context.Background(), context.Background())
+	_, err := repo.GetCoordinates(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -114,7 +138,7 @@ func TestCoordinatesRepository_ErrorJsonDecoder(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewCoordinatesRepository(server.URL)
+	repo := repository.NewCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -125,7 +149,7 @@ func TestCoordinatesRepository_ErrorJsonDecoder(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetCoordinates(address, context.Background(), context.Background())
+	_, err := repo.GetCoordinates(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -143,7 +167,7 @@ func TestCoordinatesRepository_JsonBlank(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewCoordinatesRepository(server.URL)
+	repo := repository.NewCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -154,7 +178,7 @@ func TestCoordinatesRepository_JsonBlank(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetCoordinates(address, context.Background(), context.Background())
+	_, err := repo.GetCoordinates(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -163,4 +187,37 @@ func TestCoordinatesRepository_JsonBlank(t *testing.T) {
 	if !strings.Contains(err.Error(), expectedErrorMsg) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
+
+	if !errors.Is(err, apperrors.ErrLocationNotFound) || apperrors.IsUpstreamFailure(err) {
+		t.Errorf("Expected ErrLocationNotFound, not counted as an upstream failure, got %v", err)
+	}
+}
+
+func TestCoordinatesRepository_EscapesQuery(t *testing.T) {
+	city := "Santa Bárbara d'Oeste & Região"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("city") != city {
+			t.Errorf("Query city mismatch: expected %v, got %v", city, r.URL.Query().Get("city"))
+		}
+
+		if len(r.URL.Query()) != 4 {
+			t.Errorf("Expected 4 query parameters, got %v", r.URL.Query())
+		}
+
+		w.Write([]byte(`[{"lat":"123","lon":"321"}]`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	address := &model.Address{
+		City:  city,
+		State: "SP",
+	}
+
+	_, err := repo.GetCoordinates(address, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
 }
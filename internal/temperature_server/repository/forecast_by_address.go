@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"go.opentelemetry.io/otel"
+)
+
+// wttrInForecastDays is how far ahead wttr.in forecasts.
+const wttrInForecastDays = 3
+
+type ForecastByAddressRepository interface {
+	GetForecast(*model.Address, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error)
+}
+
+type forecastByAddressRepository struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+func NewForecastByAddressRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) ForecastByAddressRepository {
+	return &forecastByAddressRepository{
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (r *forecastByAddressRepository) GetForecast(address *model.Address, query model.ForecastQuery, ctx context.Context) (_ []model.ForecastPoint, err error) {
+	tracer := otel.Tracer("ForecastByAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "ForecastByAddressRepository.GetForecast")
+	defer telemetry.EndSpan(span, &err)
+
+	if query.Days > wttrInForecastDays {
+		return nil, fmt.Errorf("%w: wttr.in forecasts at most %d days, got %d", apperrors.ErrInvalidForecast, wttrInForecastDays, query.Days)
+	}
+
+	defer telemetry.RecordUpstreamCall("wttr.in", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "wttr.in forecast request failed", &err, ctx, "city", address.City, "state", address.State)
+
+	location := fmt.Sprintf("%s,%s,Brazil", utils.CleanString(address.City), utils.CleanString(address.State))
+
+	forecastURL, err := utils.BuildURL(r.baseURL, []string{location}, url.Values{"format": {"j1"}})
+	if err != nil {
+		return nil, fmt.Errorf("error when building forecast api url: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, forecastURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport("wttr.in", err, "error when searching for weather forecast")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.Status("wttr.in", resp.StatusCode, "forecast api returned status %d", resp.StatusCode)
+	}
+
+	var forecast struct {
+		Weather []struct {
+			Date     string  `json:"date"`
+			MinTempC *string `json:"mintempC"`
+			MaxTempC *string `json:"maxtempC"`
+			Hourly   []struct {
+				Time         string  `json:"time"`
+				TempC        *string `json:"tempC"`
+				ChanceOfRain *string `json:"chanceofrain"`
+				WeatherCode  *string `json:"weatherCode"`
+			} `json:"hourly"`
+		} `json:"weather"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+		return nil, apperrors.BadPayload("wttr.in", err, "error parsing json")
+	}
+
+	if len(forecast.Weather) == 0 {
+		return nil, apperrors.BadPayload("wttr.in", nil, "forecast error: weather is missing")
+	}
+
+	var points []model.ForecastPoint
+
+	for _, day := range forecast.Weather[:min(query.Days, len(forecast.Weather))] {
+		daily := model.ForecastPoint{
+			Time:       day.Date,
+			MinCelsius: parseOptionalFloat(day.MinTempC),
+			MaxCelsius: parseOptionalFloat(day.MaxTempC),
+		}
+
+		// wttr.in forecasts every 3 hours. The day takes the highest chance of
+		// rain and the condition around midday.
+		for _, hour := range day.Hourly {
+			hourly := model.ForecastPoint{
+				Celsius:                  parseOptionalFloat(hour.TempC),
+				PrecipitationProbability: parseOptionalFloat(hour.ChanceOfRain),
+				Condition:                wttrInCondition(hour.WeatherCode),
+			}
+
+			hourOfDay, err := strconv.Atoi(strings.TrimSpace(hour.Time))
+			if err != nil {
+				return nil, apperrors.BadPayload("wttr.in", err, "forecast error: invalid hourly time %q", hour.Time)
+			}
+
+			hourly.Time = fmt.Sprintf("%sT%02d:00", day.Date, hourOfDay/100)
+
+			if query.Granularity == model.GranularityHourly {
+				points = append(points, hourly)
+			}
+
+			if hourly.PrecipitationProbability != nil && (daily.PrecipitationProbability == nil || *hourly.PrecipitationProbability > *daily.PrecipitationProbability) {
+				daily.PrecipitationProbability = hourly.PrecipitationProbability
+			}
+
+			if hourOfDay == 1200 {
+				daily.Condition = hourly.Condition
+			}
+		}
+
+		if query.Granularity == model.GranularityDaily {
+			points = append(points, daily)
+		}
+	}
+
+	return points, nil
+}
+
+func wttrInCondition(weatherCode *string) *model.Condition {
+	code := parseOptionalFloat(weatherCode)
+	if code == nil {
+		return nil
+	}
+
+	wmo := wttrInWeatherCode(int(*code))
+	if wmo == nil {
+		return nil
+	}
+
+	return model.NewCondition(*wmo)
+}
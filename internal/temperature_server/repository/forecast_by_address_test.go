@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+const wttrInForecastBody = `{"weather":[
+	{"date":"2024-08-30","mintempC":"-1","maxtempC":"14","hourly":[
+		{"time":"0","tempC":"0","chanceofrain":"0","weatherCode":"113"},
+		{"time":"1200","tempC":"13","chanceofrain":"40","weatherCode":"116"},
+		{"time":"2100","tempC":"5","chanceofrain":"75","weatherCode":"296"}]},
+	{"date":"2024-08-31","mintempC":"2","maxtempC":"16","hourly":[
+		{"time":"1200","tempC":"15","chanceofrain":"10","weatherCode":"113"}]}]}`
+
+func TestForecastByAddressRepository_Daily(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Cidade,Estado,Brazil" || r.URL.Query().Get("format") != "j1" {
+			t.Errorf("Unexpected request %v", r.URL)
+		}
+
+		w.Write([]byte(wttrInForecastBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewForecastByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	points, err := repo.GetForecast(&model.Address{City: "Cidade", State: "Estado"}, model.ForecastQuery{Days: 1, Granularity: model.GranularityDaily}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(points) != 1 {
+		t.Fatalf("Expected 1 point, got %d", len(points))
+	}
+
+	day := points[0]
+	if day.Time != "2024-08-30" || *day.MinCelsius != -1 || *day.MaxCelsius != 14 || *day.PrecipitationProbability != 75 {
+		t.Errorf("Unexpected day %+v", day)
+	}
+
+	// The day takes the midday condition, partly cloudy.
+	if day.Condition == nil || day.Condition.Code != 2 {
+		t.Errorf("Expected partly cloudy, got %+v", day.Condition)
+	}
+}
+
+func TestForecastByAddressRepository_Hourly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(wttrInForecastBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewForecastByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	points, err := repo.GetForecast(&model.Address{City: "Cidade", State: "Estado"}, model.ForecastQuery{Days: 2, Granularity: model.GranularityHourly}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(points) != 4 {
+		t.Fatalf("Expected 4 points, got %d", len(points))
+	}
+
+	if points[0].Time != "2024-08-30T00:00" || *points[0].Celsius != 0 || points[2].Time != "2024-08-30T21:00" || points[3].Time != "2024-08-31T12:00" {
+		t.Errorf("Unexpected points %+v", points)
+	}
+}
+
+func TestForecastByAddressRepository_TooManyDays(t *testing.T) {
+	repo := repository.NewForecastByAddressRepository("http://localhost", http.DefaultClient, time.Second, logging.Discard())
+
+	_, err := repo.GetForecast(&model.Address{City: "Cidade", State: "Estado"}, model.ForecastQuery{Days: 5, Granularity: model.GranularityDaily}, context.Background())
+	if !errors.Is(err, apperrors.ErrInvalidForecast) || !strings.Contains(err.Error(), "at most 3 days") {
+		t.Errorf("Expected a days error, got %v", err)
+	}
+}
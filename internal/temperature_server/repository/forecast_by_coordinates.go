@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"go.opentelemetry.io/otel"
+)
+
+type ForecastByCoordinatesRepository interface {
+	GetForecast(*model.Coordinates, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error)
+}
+
+type forecastByCoordinatesRepository struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+func NewForecastByCoordinatesRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) ForecastByCoordinatesRepository {
+	return &forecastByCoordinatesRepository{
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// openMeteoSeries holds the arrays of an open-meteo hourly or daily block. The
+// values are pointers, as open-meteo answers null for the hours it has no
+// data for.
+type openMeteoSeries struct {
+	Time                        []string   `json:"time"`
+	Temperature                 []*float64 `json:"temperature_2m"`
+	TemperatureMin              []*float64 `json:"temperature_2m_min"`
+	TemperatureMax              []*float64 `json:"temperature_2m_max"`
+	PrecipitationProbability    []*float64 `json:"precipitation_probability"`
+	PrecipitationProbabilityMax []*float64 `json:"precipitation_probability_max"`
+	WeatherCode                 []*int     `json:"weather_code"`
+}
+
+func (r *forecastByCoordinatesRepository) GetForecast(coordinates *model.Coordinates, query model.ForecastQuery, ctx context.Context) (_ []model.ForecastPoint, err error) {
+	tracer := otel.Tracer("ForecastByCoordinatesRepository")
+
+	ctx, span := tracer.Start(ctx, "ForecastByCoordinatesRepository.GetForecast")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("open-meteo", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "open-meteo forecast request failed", &err, ctx, "latitude", coordinates.Latitude, "longitude", coordinates.Longitude)
+
+	params := url.Values{}
+	params.Add("latitude", coordinates.Latitude)
+	params.Add("longitude", coordinates.Longitude)
+	params.Add("timezone", "auto")
+	params.Add("forecast_days", strconv.Itoa(query.Days))
+
+	if query.Granularity == model.GranularityHourly {
+		params.Add("hourly", "temperature_2m,precipitation_probability,weather_code")
+	} else {
+		params.Add("daily", "temperature_2m_min,temperature_2m_max,precipitation_probability_max,weather_code")
+	}
+
+	forecastURL, err := utils.BuildURL(r.baseURL, []string{"v1", "forecast"}, params)
+	if err != nil {
+		return nil, fmt.Errorf("error when building forecast api url: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, forecastURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport("open-meteo", err, "error when searching for weather forecast")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.Status("open-meteo", resp.StatusCode, "forecast api returned status %d", resp.StatusCode)
+	}
+
+	var forecast struct {
+		Hourly openMeteoSeries `json:"hourly"`
+		Daily  openMeteoSeries `json:"daily"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+		return nil, apperrors.BadPayload("open-meteo", err, "error parsing json")
+	}
+
+	series := forecast.Daily
+	if query.Granularity == model.GranularityHourly {
+		series = forecast.Hourly
+	}
+
+	if len(series.Time) == 0 {
+		return nil, apperrors.BadPayload("open-meteo", nil, "forecast error: %s series is missing", query.Granularity)
+	}
+
+	points := make([]model.ForecastPoint, len(series.Time))
+
+	for i, pointTime := range series.Time {
+		points[i] = model.ForecastPoint{
+			Time:                     pointTime,
+			Celsius:                  at(series.Temperature, i),
+			MinCelsius:               at(series.TemperatureMin, i),
+			MaxCelsius:               at(series.TemperatureMax, i),
+			PrecipitationProbability: at(series.PrecipitationProbability, i),
+		}
+
+		if query.Granularity == model.GranularityDaily {
+			points[i].PrecipitationProbability = at(series.PrecipitationProbabilityMax, i)
+		}
+
+		if code := at(series.WeatherCode, i); code != nil {
+			points[i].Condition = model.NewCondition(*code)
+		}
+	}
+
+	return points, nil
+}
+
+// at returns the i-th value of an open-meteo array, or nil when the array is
+// shorter than the time series.
+func at[T any](values []*T, i int) *T {
+	if i >= len(values) {
+		return nil
+	}
+
+	return values[i]
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+func TestForecastByCoordinatesRepository_Daily(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if r.URL.Path != "/v1/forecast" || query.Get("forecast_days") != "2" || query.Get("timezone") != "auto" {
+			t.Errorf("Unexpected request %v", r.URL)
+		}
+
+		if query.Get("daily") != "temperature_2m_min,temperature_2m_max,precipitation_probability_max,weather_code" || query.Has("hourly") {
+			t.Errorf("Unexpected series %v", r.URL)
+		}
+
+		responseBody := `{"daily":{"time":["2024-08-30","2024-08-31"],"temperature_2m_min":[-2.1,3],` +
+			`"temperature_2m_max":[12.4,null],"precipitation_probability_max":[10,80],"weather_code":[0,63]}}`
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewForecastByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	points, err := repo.GetForecast(&model.Coordinates{Latitude: "1", Longitude: "2"}, model.ForecastQuery{Days: 2, Granularity: model.GranularityDaily}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(points))
+	}
+
+	first := points[0]
+	if first.Time != "2024-08-30" || *first.MinCelsius != -2.1 || *first.MaxCelsius != 12.4 || *first.PrecipitationProbability != 10 || first.Celsius != nil {
+		t.Errorf("Unexpected first point %+v", first)
+	}
+
+	if first.Condition == nil || first.Condition.Code != 0 {
+		t.Errorf("Expected clear sky, got %+v", first.Condition)
+	}
+
+	if points[1].MaxCelsius != nil || points[1].Condition.Code != 63 {
+		t.Errorf("Unexpected second point %+v", points[1])
+	}
+}
+
+func TestForecastByCoordinatesRepository_Hourly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("hourly") != "temperature_2m,precipitation_probability,weather_code" {
+			t.Errorf("Unexpected series %v", r.URL)
+		}
+
+		responseBody := `{"hourly":{"time":["2024-08-30T00:00","2024-08-30T01:00"],"temperature_2m":[4.2,3.9],` +
+			`"precipitation_probability":[0,5],"weather_code":[1,2]}}`
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewForecastByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	points, err := repo.GetForecast(&model.Coordinates{Latitude: "1", Longitude: "2"}, model.ForecastQuery{Days: 1, Granularity: model.GranularityHourly}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(points) != 2 || points[1].Time != "2024-08-30T01:00" || *points[1].Celsius != 3.9 || *points[1].PrecipitationProbability != 5 {
+		t.Errorf("Unexpected points %+v", points)
+	}
+}
+
+func TestForecastByCoordinatesRepository_MissingSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"daily":{}}`))
+	}))
+	defer server.Close()
+
+	repo := repository.NewForecastByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	_, err := repo.GetForecast(&model.Coordinates{Latitude: "1", Longitude: "2"}, model.ForecastQuery{Days: 1, Granularity: model.GranularityDaily}, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "daily series is missing") {
+		t.Errorf("Expected a missing series error, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"go.opentelemetry.io/otel"
+)
+
+type HistoryRepository interface {
+	GetHistory(*model.Coordinates, model.HistoryQuery, context.Context) ([]model.HistoryDay, error)
+}
+
+type historyRepository struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewHistoryRepository queries the open-meteo weather archive, which answers
+// the daily aggregates of past days.
+func NewHistoryRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) HistoryRepository {
+	return &historyRepository{
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (r *historyRepository) GetHistory(coordinates *model.Coordinates, query model.HistoryQuery, ctx context.Context) (_ []model.HistoryDay, err error) {
+	tracer := otel.Tracer("HistoryRepository")
+
+	ctx, span := tracer.Start(ctx, "HistoryRepository.GetHistory")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("open-meteo archive", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "open-meteo archive request failed", &err, ctx, "latitude", coordinates.Latitude, "longitude", coordinates.Longitude)
+
+	params := url.Values{}
+	params.Add("latitude", coordinates.Latitude)
+	params.Add("longitude", coordinates.Longitude)
+	params.Add("start_date", query.Start.Format(model.DateLayout))
+	params.Add("end_date", query.End.Format(model.DateLayout))
+	params.Add("daily", "temperature_2m_min,temperature_2m_max,temperature_2m_mean,precipitation_sum")
+	params.Add("timezone", "auto")
+
+	historyURL, err := utils.BuildURL(r.baseURL, []string{"v1", "archive"}, params)
+	if err != nil {
+		return nil, fmt.Errorf("error when building archive api url: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, historyURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport("open-meteo archive", err, "error when searching for weather history")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.Status("open-meteo archive", resp.StatusCode, "archive api returned status %d", resp.StatusCode)
+	}
+
+	var history struct {
+		Daily struct {
+			Time             []string   `json:"time"`
+			TemperatureMin   []*float64 `json:"temperature_2m_min"`
+			TemperatureMax   []*float64 `json:"temperature_2m_max"`
+			TemperatureMean  []*float64 `json:"temperature_2m_mean"`
+			PrecipitationSum []*float64 `json:"precipitation_sum"`
+		} `json:"daily"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return nil, apperrors.BadPayload("open-meteo archive", err, "error parsing json")
+	}
+
+	if len(history.Daily.Time) == 0 {
+		return nil, apperrors.BadPayload("open-meteo archive", nil, "history error: daily series is missing")
+	}
+
+	days := make([]model.HistoryDay, len(history.Daily.Time))
+
+	for i, date := range history.Daily.Time {
+		days[i] = model.HistoryDay{
+			Date:            date,
+			MinCelsius:      at(history.Daily.TemperatureMin, i),
+			MaxCelsius:      at(history.Daily.TemperatureMax, i),
+			MeanCelsius:     at(history.Daily.TemperatureMean, i),
+			PrecipitationMm: at(history.Daily.PrecipitationSum, i),
+		}
+	}
+
+	return days, nil
+}
@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+var historyQuery = model.HistoryQuery{
+	Start: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC),
+	End:   time.Date(2024, time.July, 2, 0, 0, 0, 0, time.UTC),
+}
+
+func TestHistoryRepository_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if r.URL.Path != "/v1/archive" || query.Get("start_date") != "2024-07-01" || query.Get("end_date") != "2024-07-02" || query.Get("latitude") != "-28.28" {
+			t.Errorf("Unexpected request %v", r.URL)
+		}
+
+		if query.Get("daily") != "temperature_2m_min,temperature_2m_max,temperature_2m_mean,precipitation_sum" {
+			t.Errorf("Unexpected series %v", r.URL)
+		}
+
+		responseBody := `{"daily":{"time":["2024-07-01","2024-07-02"],"temperature_2m_min":[-5.2,-3],` +
+			`"temperature_2m_max":[8.1,9],"temperature_2m_mean":[1.4,null],"precipitation_sum":[0,2.5]}}`
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewHistoryRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	days, err := repo.GetHistory(&model.Coordinates{Latitude: "-28.28", Longitude: "-49.93"}, historyQuery, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(days))
+	}
+
+	if days[0].Date != "2024-07-01" || *days[0].MinCelsius != -5.2 || *days[0].MeanCelsius != 1.4 || *days[0].PrecipitationMm != 0 || !days[0].Complete() {
+		t.Errorf("Unexpected first day %+v", days[0])
+	}
+
+	if days[1].MeanCelsius != nil || days[1].Complete() {
+		t.Errorf("Expected the second day to be incomplete, got %+v", days[1])
+	}
+}
+
+type CountingHistoryRepository struct {
+	Days  []model.HistoryDay
+	Err   error
+	Calls int
+}
+
+func (m *CountingHistoryRepository) GetHistory(*model.Coordinates, model.HistoryQuery, context.Context) ([]model.HistoryDay, error) {
+	m.Calls++
+	return m.Days, m.Err
+}
+
+func TestCachedHistoryRepository_OnlyCachesCompleteDays(t *testing.T) {
+	value := 1.0
+	complete := model.HistoryDay{Date: "2024-07-01", MinCelsius: &value, MaxCelsius: &value, MeanCelsius: &value, PrecipitationMm: &value}
+	incomplete := model.HistoryDay{Date: "2024-07-02", MinCelsius: &value}
+
+	tests := []struct {
+		name          string
+		days          []model.HistoryDay
+		expectedCalls int
+	}{
+		{"complete", []model.HistoryDay{complete}, 1},
+		{"incomplete", []model.HistoryDay{complete, incomplete}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := &CountingHistoryRepository{Days: test.days}
+
+			repo := repository.NewCachedHistoryRepository(next, cache.New[string, []model.HistoryDay](10, time.Minute))
+
+			for range 2 {
+				if _, err := repo.GetHistory(&model.Coordinates{Latitude: "1", Longitude: "2"}, historyQuery, context.Background()); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+
+			if next.Calls != test.expectedCalls {
+				t.Errorf("Expected %d calls to the wrapped repository, got %d", test.expectedCalls, next.Calls)
+			}
+		})
+	}
+}
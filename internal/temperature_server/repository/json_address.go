@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+	"go.opentelemetry.io/otel"
+)
+
+// jsonAddressRepository finds addresses through a provider answering the
+// address of a zipcode as a JSON payload of type T at a path of its own, and
+// an unknown zipcode with 404. address maps the payload, or returns nil when
+// the payload does not hold a zipcode.
+type jsonAddressRepository[T any] struct {
+	upstream string
+	baseURL  string
+	path     func(cep.CEP) []string
+	address  func(T) *model.Address
+	client   *http.Client
+	timeout  time.Duration
+	logger   *slog.Logger
+}
+
+func (r *jsonAddressRepository[T]) GetAddress(zipcode string, ctx context.Context) (_ *model.Address, err error) {
+	tracer := otel.Tracer(r.upstream + "AddressRepository")
+
+	ctx, span := tracer.Start(ctx, r.upstream+"AddressRepository.GetAddress")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall(r.upstream, time.Now(), &err, ctx)
+	defer logging.Error(r.logger, r.upstream+" request failed", &err, ctx, "cep", zipcode)
+
+	parsed, err := cep.Parse(zipcode)
+	if err != nil {
+		return nil, err
+	}
+
+	addressURL, err := utils.BuildURL(r.baseURL, r.path(parsed), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when building %s url: %w", r.upstream, err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addressURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, apperrors.Transport(r.upstream, err, "error when searching for zipcode %s information", parsed)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, apperrors.ErrCEPNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.Status(r.upstream, resp.StatusCode, "%s api returned status %d for zipcode %s", r.upstream, resp.StatusCode, parsed)
+	}
+
+	var payload T
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, apperrors.BadPayload(r.upstream, err, "error when decoding %s api response to zipcode %s", r.upstream, parsed)
+	}
+
+	address := r.address(payload)
+	if address == nil {
+		return nil, apperrors.ErrCEPNotFound
+	}
+
+	address.PostalCode = parsed.Formatted()
+
+	return address, nil
+}
@@ -0,0 +1,108 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+var jsonAddressProviders = []struct {
+	name     string
+	new      func(string, *http.Client, time.Duration, *slog.Logger) repository.AddressRepository
+	path     string
+	payload  string
+	notFound string
+	expected model.Address
+}{
+	{
+		name:     "BrasilAPI",
+		new:      repository.NewBrasilAPIAddressRepository,
+		path:     "/api/cep/v1/01001000",
+		payload:  `{"cep":"01001000","state":"SP","city":"São Paulo","neighborhood":"Sé","street":"Praça da Sé","service":"viacep"}`,
+		notFound: `{"name":"CepPromiseError","message":"Todos os serviços de CEP retornaram erro.","type":"service_error"}`,
+		expected: model.Address{PostalCode: "01001-000", Street: "Praça da Sé", District: "Sé", City: "São Paulo", State: "SP"},
+	},
+	{
+		name:     "OpenCEP",
+		new:      repository.NewOpenCEPAddressRepository,
+		path:     "/v1/01001000",
+		payload:  `{"cep":"01001-000","logradouro":"Praça da Sé","complemento":"lado ímpar","bairro":"Sé","localidade":"São Paulo","uf":"SP","ibge":"3550308"}`,
+		notFound: `{"error":"CEP não encontrado"}`,
+		expected: model.Address{PostalCode: "01001-000", Street: "Praça da Sé", Complement: "lado ímpar", District: "Sé", City: "São Paulo", State: "SP"},
+	},
+	{
+		name:     "AwesomeAPI",
+		new:      repository.NewAwesomeAPIAddressRepository,
+		path:     "/json/01001000",
+		payload:  `{"cep":"01001000","address_type":"Praça","address_name":"da Sé","address":"Praça da Sé","state":"SP","district":"Sé","lat":"-23.5502784","lng":"-46.6342179","city":"São Paulo","city_ibge":"3550308","ddd":"11"}`,
+		notFound: `{"code":"not_found","message":"O CEP 01001999 nao foi encontrado"}`,
+		expected: model.Address{PostalCode: "01001-000", Street: "Praça da Sé", District: "Sé", City: "São Paulo", State: "SP"},
+	},
+}
+
+func TestJSONAddressRepository_Success(t *testing.T) {
+	for _, provider := range jsonAddressProviders {
+		t.Run(provider.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != provider.path {
+					t.Errorf("Path mismatch: expected %v, got %v", provider.path, r.URL.Path)
+				}
+
+				w.Write([]byte(provider.payload))
+			}))
+			defer server.Close()
+
+			repo := provider.new(server.URL, server.Client(), time.Second, logging.Discard())
+
+			address, err := repo.GetAddress("01001-000", context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if *address != provider.expected {
+				t.Errorf("Address mismatch: expected %+v, got %+v", provider.expected, *address)
+			}
+		})
+	}
+}
+
+func TestJSONAddressRepository_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     func(notFound string) string
+		expected error
+	}{
+		{"not found", http.StatusNotFound, func(notFound string) string { return notFound }, apperrors.ErrCEPNotFound},
+		{"empty payload", http.StatusOK, func(string) string { return `{}` }, apperrors.ErrCEPNotFound},
+		{"bad payload", http.StatusOK, func(string) string { return `{"cep":` }, apperrors.ErrUpstreamBadPayload},
+		{"not status ok", http.StatusInternalServerError, func(string) string { return "" }, apperrors.ErrUpstreamUnavailable},
+	}
+
+	for _, provider := range jsonAddressProviders {
+		for _, test := range tests {
+			t.Run(provider.name+"/"+test.name, func(t *testing.T) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(test.status)
+					w.Write([]byte(test.body(provider.notFound)))
+				}))
+				defer server.Close()
+
+				repo := provider.new(server.URL, server.Client(), time.Second, logging.Discard())
+
+				if _, err := repo.GetAddress("01001999", context.Background()); !errors.Is(err, test.expected) {
+					t.Errorf("Expected %v, got %v", test.expected, err)
+				}
+			})
+		}
+	}
+}
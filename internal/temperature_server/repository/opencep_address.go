@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+)
+
+// OpenCEP mirrors the fields of ViaCEP, but answers an unknown zipcode with
+// 404 instead of an error flag.
+type openCEPAddress struct {
+	CEP        string `json:"cep"`
+	Street     string `json:"logradouro"`
+	Complement string `json:"complemento"`
+	District   string `json:"bairro"`
+	City       string `json:"localidade"`
+	State      string `json:"uf"`
+}
+
+func NewOpenCEPAddressRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) AddressRepository {
+	return &jsonAddressRepository[openCEPAddress]{
+		upstream: "OpenCEP",
+		baseURL:  baseURL,
+		path: func(zipcode cep.CEP) []string {
+			return []string{"v1", zipcode.String()}
+		},
+		address: func(address openCEPAddress) *model.Address {
+			if address.CEP == "" {
+				return nil
+			}
+
+			return &model.Address{
+				Street:     address.Street,
+				Complement: address.Complement,
+				District:   address.District,
+				City:       address.City,
+				State:      address.State,
+			}
+		},
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
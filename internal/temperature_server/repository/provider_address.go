@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The strategies to pick the provider that answers an address. Fallback asks
+// the providers in order until one answers, while race asks all of them at
+// once and keeps the first answer.
+const (
+	AddressFallback = "fallback"
+	AddressRace     = "race"
+)
+
+// AddressProvider is an address repository known by the name used in the
+// configuration.
+type AddressProvider interface {
+	AddressRepository
+	Name() string
+}
+
+type addressProvider struct {
+	AddressRepository
+	name string
+}
+
+func NewAddressProvider(name string, repository AddressRepository) AddressProvider {
+	return &addressProvider{
+		AddressRepository: repository,
+		name:              name,
+	}
+}
+
+func (p *addressProvider) Name() string {
+	return p.name
+}
+
+type providerAddressRepository struct {
+	providers []AddressProvider
+	strategy  string
+	logger    *slog.Logger
+}
+
+// NewProviderAddressRepository orders the available providers by name, as
+// listed in the configuration, and asks them following the strategy.
+// Providers that are not listed are left out.
+func NewProviderAddressRepository(names []string, strategy string, logger *slog.Logger, available ...AddressProvider) (AddressRepository, error) {
+	if strategy != AddressFallback && strategy != AddressRace {
+		return nil, fmt.Errorf("unknown address strategy %q", strategy)
+	}
+
+	providersByName := make(map[string]AddressProvider, len(available))
+	for _, provider := range available {
+		providersByName[provider.Name()] = provider
+	}
+
+	providers := make([]AddressProvider, 0, len(names))
+
+	for _, name := range names {
+		provider, ok := providersByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown address provider %q", name)
+		}
+
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("at least one address provider must be configured")
+	}
+
+	return &providerAddressRepository{
+		providers: providers,
+		strategy:  strategy,
+		logger:    logger,
+	}, nil
+}
+
+// GetAddress answers the address of the first provider that finds it. A
+// provider that does not know the zipcode does not stop the others, as their
+// databases are not updated at the same pace, so the zipcode is only not found
+// when no provider answers it.
+func (r *providerAddressRepository) GetAddress(zipcode string, ctx context.Context) (_ *model.Address, err error) {
+	tracer := otel.Tracer("ProviderAddressRepository")
+
+	ctx, span := tracer.Start(ctx, "ProviderAddressRepository.GetAddress")
+	defer telemetry.EndSpan(span, &err)
+
+	parsed, err := cep.Parse(zipcode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse only accepts the zipcodes that fall in the range of a state.
+	state, _ := parsed.State()
+	span.SetAttributes(
+		attribute.String("cep.state", state),
+		attribute.String("address.strategy", r.strategy),
+	)
+
+	getAddress := r.fallback
+	if r.strategy == AddressRace {
+		getAddress = r.race
+	}
+
+	address, index, err := getAddress(parsed.String(), ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting address from every provider: %w", err)
+	}
+
+	provider := r.providers[index].Name()
+	address.Provider = provider
+
+	span.SetAttributes(attribute.String("address.provider", provider))
+	telemetry.RecordAddressProvider(provider, index > 0, ctx)
+
+	// The answer of the provider is kept, but a state that disagrees with the
+	// range of the zipcode is recorded to be looked into.
+	if address.State != state {
+		span.AddEvent("cep.state_mismatch", trace.WithAttributes(
+			attribute.String("cep.state", state),
+			attribute.String("address.state", address.State),
+			attribute.String("address.provider", provider),
+		))
+		r.logger.WarnContext(ctx, "address state does not match the zipcode range", "cep", parsed, "state", state, "address_state", address.State, "provider", provider)
+	}
+
+	return address, nil
+}
+
+func (r *providerAddressRepository) fallback(zipcode string, ctx context.Context) (*model.Address, int, error) {
+	var errs []error
+
+	for i, provider := range r.providers {
+		address, err := provider.GetAddress(zipcode, ctx)
+		if err == nil {
+			return address, i, nil
+		}
+
+		errs = append(errs, r.providerFailed(provider, err, ctx))
+	}
+
+	return nil, 0, errors.Join(errs...)
+}
+
+// race cancels the providers still running once one of them answers, which
+// the circuit breakers do not count as failures.
+func (r *providerAddressRepository) race(zipcode string, ctx context.Context) (*model.Address, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type answer struct {
+		address *model.Address
+		index   int
+		err     error
+	}
+
+	answers := make(chan answer, len(r.providers))
+
+	for i, provider := range r.providers {
+		go func() {
+			address, err := provider.GetAddress(zipcode, ctx)
+			answers <- answer{address: address, index: i, err: err}
+		}()
+	}
+
+	// Kept in the order of the chain, whatever the order the providers fail.
+	errs := make([]error, len(r.providers))
+
+	for range r.providers {
+		answer := <-answers
+		if answer.err == nil {
+			return answer.address, answer.index, nil
+		}
+
+		errs[answer.index] = r.providerFailed(r.providers[answer.index], answer.err, ctx)
+	}
+
+	return nil, 0, errors.Join(errs...)
+}
+
+func (r *providerAddressRepository) providerFailed(provider AddressProvider, err error, ctx context.Context) error {
+	span := trace.SpanFromContext(ctx)
+
+	span.RecordError(err, trace.WithAttributes(attribute.String("address.provider", provider.Name())))
+	r.logger.WarnContext(ctx, "address provider failed", "provider", provider.Name(), "error", err)
+
+	return fmt.Errorf("%s: %w", provider.Name(), err)
+}
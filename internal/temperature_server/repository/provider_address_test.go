@@ -0,0 +1,170 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// BlockingAddressRepository only answers once its context is done, standing
+// for a provider that lost the race.
+type BlockingAddressRepository struct {
+	Cancelled chan struct{}
+}
+
+func (m *BlockingAddressRepository) GetAddress(_ string, ctx context.Context) (*model.Address, error) {
+	<-ctx.Done()
+	close(m.Cancelled)
+
+	return nil, ctx.Err()
+}
+
+func TestProviderAddressRepository_FallsBackInOrder(t *testing.T) {
+	viaCEP := &CountingAddressRepository{Err: apperrors.Status("ViaCEP", http.StatusBadGateway, "ViaCEP api returned status 502")}
+	brasilAPI := &CountingAddressRepository{Address: &model.Address{PostalCode: "01001-000", City: "São Paulo", State: "SP"}}
+	openCEP := &CountingAddressRepository{Address: &model.Address{PostalCode: "01001-000", City: "São Paulo", State: "SP"}}
+
+	repo, err := repository.NewProviderAddressRepository(
+		[]string{"viacep", "brasilapi", "opencep"},
+		repository.AddressFallback,
+		logging.Discard(),
+		repository.NewAddressProvider("opencep", openCEP),
+		repository.NewAddressProvider("brasilapi", brasilAPI),
+		repository.NewAddressProvider("viacep", viaCEP),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	address, err := repo.GetAddress("01001-000", context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if address.Provider != "brasilapi" {
+		t.Errorf("Expected the address of brasilapi, got %q", address.Provider)
+	}
+
+	if viaCEP.Calls != 1 || brasilAPI.Calls != 1 || openCEP.Calls != 0 {
+		t.Errorf("Unexpected calls: viacep %d, brasilapi %d, opencep %d", viaCEP.Calls, brasilAPI.Calls, openCEP.Calls)
+	}
+}
+
+func TestProviderAddressRepository_NotFoundByEveryProvider(t *testing.T) {
+	viaCEP := &CountingAddressRepository{Err: apperrors.ErrCEPNotFound}
+	brasilAPI := &CountingAddressRepository{Err: apperrors.ErrCEPNotFound}
+
+	repo, _ := repository.NewProviderAddressRepository(
+		[]string{"viacep", "brasilapi"},
+		repository.AddressFallback,
+		logging.Discard(),
+		repository.NewAddressProvider("viacep", viaCEP),
+		repository.NewAddressProvider("brasilapi", brasilAPI),
+	)
+
+	_, err := repo.GetAddress("01001000", context.Background())
+	if !errors.Is(err, apperrors.ErrCEPNotFound) {
+		t.Errorf("Expected ErrCEPNotFound, got %v", err)
+	}
+
+	if brasilAPI.Calls != 1 {
+		t.Errorf("Expected the zipcode unknown to ViaCEP to be asked to BrasilAPI, got %d calls", brasilAPI.Calls)
+	}
+}
+
+func TestProviderAddressRepository_RaceKeepsTheFirstAnswer(t *testing.T) {
+	slow := &BlockingAddressRepository{Cancelled: make(chan struct{})}
+	fast := &CountingAddressRepository{Address: &model.Address{PostalCode: "01001-000", City: "São Paulo", State: "SP"}}
+
+	repo, _ := repository.NewProviderAddressRepository(
+		[]string{"viacep", "awesomeapi"},
+		repository.AddressRace,
+		logging.Discard(),
+		repository.NewAddressProvider("viacep", slow),
+		repository.NewAddressProvider("awesomeapi", fast),
+	)
+
+	address, err := repo.GetAddress("01001000", context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if address.Provider != "awesomeapi" {
+		t.Errorf("Expected the address of awesomeapi, got %q", address.Provider)
+	}
+
+	// Blocks until the provider that lost the race is cancelled.
+	<-slow.Cancelled
+}
+
+func TestProviderAddressRepository_RaceFailsWhenEveryProviderFails(t *testing.T) {
+	repo, _ := repository.NewProviderAddressRepository(
+		[]string{"viacep", "opencep"},
+		repository.AddressRace,
+		logging.Discard(),
+		repository.NewAddressProvider("viacep", &CountingAddressRepository{Err: apperrors.Status("ViaCEP", http.StatusServiceUnavailable, "ViaCEP api returned status 503")}),
+		repository.NewAddressProvider("opencep", &CountingAddressRepository{Err: apperrors.Status("OpenCEP", http.StatusServiceUnavailable, "OpenCEP api returned status 503")}),
+	)
+
+	_, err := repo.GetAddress("01001000", context.Background())
+	if !errors.Is(err, apperrors.ErrUpstreamUnavailable) {
+		t.Errorf("Expected ErrUpstreamUnavailable, got %v", err)
+	}
+}
+
+func TestProviderAddressRepository_StateMismatch(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	repo, _ := repository.NewProviderAddressRepository(
+		[]string{"viacep"},
+		repository.AddressFallback,
+		logging.Discard(),
+		repository.NewAddressProvider("viacep", &CountingAddressRepository{Address: &model.Address{PostalCode: "01001-000", City: "São Paulo", State: "RJ"}}),
+	)
+
+	address, err := repo.GetAddress("01001-000", context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if address.State != "RJ" {
+		t.Errorf("Expected the state answered by the provider, got %v", address.State)
+	}
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 1 || events[0].Name != "cep.state_mismatch" {
+		t.Errorf("Expected a single cep.state_mismatch event, got %+v", events)
+	}
+}
+
+func TestNewProviderAddressRepository_Errors(t *testing.T) {
+	viaCEP := repository.NewAddressProvider("viacep", &CountingAddressRepository{})
+
+	tests := []struct {
+		names    []string
+		strategy string
+	}{
+		{[]string{"viacep", "postmon"}, repository.AddressFallback},
+		{nil, repository.AddressFallback},
+		{[]string{"viacep"}, "fastest"},
+	}
+
+	for _, test := range tests {
+		if _, err := repository.NewProviderAddressRepository(test.names, test.strategy, logging.Discard(), viaCEP); err == nil {
+			t.Errorf("Expected an error for %v and %q, got nil", test.names, test.strategy)
+		}
+	}
+}
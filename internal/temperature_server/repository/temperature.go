@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"math"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+// The plausible air temperatures, in Celsius, a bit beyond the coldest and
+// hottest ever recorded. Anything outside them can only be a broken payload.
+const (
+	minTemperature = -90.0
+	maxTemperature = 60.0
+)
+
+func validateTemperature(upstream string, celsius float64) error {
+	if math.IsNaN(celsius) || celsius < minTemperature || celsius > maxTemperature {
+		return apperrors.BadPayload(upstream, nil, "temperature error: %v°C is out of the plausible range", celsius)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// withTimeout bounds an upstream call by the timeout configured for it. A zero
+// timeout leaves the call bounded only by the request context.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -4,70 +4,130 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
 	"go.opentelemetry.io/otel"
 )
 
 type WeatherByAddressRepository interface {
-	GetWeather(*model.Address, context.Context, context.Context) (*model.Weather, error)
+	GetWeather(*model.Address, context.Context) (*model.Weather, error)
 }
 
 type weatherByAddressRepository struct {
-	URL string
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
 }
 
-func NewWeatherByAddressRepository(url string) WeatherByAddressRepository {
+func NewWeatherByAddressRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) WeatherByAddressRepository {
 	return &weatherByAddressRepository{
-		URL: url,
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
 	}
 }
 
-func (r *weatherByAddressRepository) GetWeather(address *model.Address, ctx context.Context, ctxDistributed context.Context) (*model.Weather, error) {
+func (r *weatherByAddressRepository) GetWeather(address *model.Address, ctx context.Context) (_ *model.Weather, err error) {
 	tracer := otel.Tracer("WeatherByAddressRepository")
 
-	_, span := tracer.Start(ctx, "WeatherByAddressRepository.GetWeather")
-	defer span.End()
+	ctx, span := tracer.Start(ctx, "WeatherByAddressRepository.GetWeather")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("wttr.in", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "wttr.in request failed", &err, ctx, "city", address.City, "state", address.State)
 
-	_, spanDistributed := tracer.Start(ctxDistributed, "WeatherByAddressRepository.GetWeather")
-	defer spanDistributed.End()
+	location := fmt.Sprintf("%s,%s,Brazil", utils.CleanString(address.City), utils.CleanString(address.State))
 
-	var url string
+	weatherURL, err := utils.BuildURL(r.baseURL, []string{location}, url.Values{"format": {"j1"}})
+	if err != nil {
+		return nil, fmt.Errorf("error when building weather api url: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	if os.Getenv("TEST") == "true" {
-		url = r.URL
-	} else {
-		url = fmt.Sprintf(r.URL, utils.CleanString(address.City), utils.CleanString(address.State))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, weatherURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
 	}
 
-	resp, err := http.Get(url)
+	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for weather forecast: %w", err)
+		return nil, apperrors.Transport("wttr.in", err, "error when searching for weather forecast")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("weather api returned status %d: %w", resp.StatusCode, err)
+		return nil, apperrors.Status("wttr.in", resp.StatusCode, "weather api returned status %d", resp.StatusCode)
 	}
 
 	var tempWeather struct {
 		CurrentCondition []struct {
-			TempC string `json:"temp_C"`
+			TempC         *string `json:"temp_C"`
+			FeelsLikeC    *string `json:"FeelsLikeC"`
+			Humidity      *string `json:"humidity"`
+			WindSpeedKmph *string `json:"windspeedKmph"`
+			WindDirDegree *string `json:"winddirDegree"`
+			WeatherCode   *string `json:"weatherCode"`
 		} `json:"current_condition"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&tempWeather); err != nil {
-		return nil, fmt.Errorf("error parsing json: %w", err)
+		return nil, apperrors.BadPayload("wttr.in", err, "error parsing json")
+	}
+
+	if len(tempWeather.CurrentCondition) == 0 || tempWeather.CurrentCondition[0].TempC == nil {
+		return nil, apperrors.BadPayload("wttr.in", nil, "temperature error: current_condition.temp_C is missing")
 	}
 
-	if len(tempWeather.CurrentCondition) > 0 {
-		weather := &model.Weather{Temperature: utils.StringToFloat64(tempWeather.CurrentCondition[0].TempC)}
+	condition := tempWeather.CurrentCondition[0]
+
+	temperature, err := strconv.ParseFloat(strings.TrimSpace(*condition.TempC), 64)
+	if err != nil {
+		return nil, apperrors.BadPayload("wttr.in", err, "temperature error: invalid temp_C %q", *condition.TempC)
+	}
+
+	if err := validateTemperature("wttr.in", temperature); err != nil {
+		return nil, err
+	}
 
-		return weather, nil
-	} else {
-		return nil, fmt.Errorf("temperature error")
+	// wttr.in answers every value as a string. The ones besides the
+	// temperature are optional, so a malformed one is only left out.
+	weather := &model.Weather{
+		Temperature:         temperature,
+		ApparentTemperature: parseOptionalFloat(condition.FeelsLikeC),
+		Humidity:            parseOptionalFloat(condition.Humidity),
+		WindSpeed:           parseOptionalFloat(condition.WindSpeedKmph),
+		WindDirection:       parseOptionalFloat(condition.WindDirDegree),
 	}
+
+	if code := parseOptionalFloat(condition.WeatherCode); code != nil {
+		weather.WeatherCode = wttrInWeatherCode(int(*code))
+	}
+
+	return weather, nil
+}
+
+func parseOptionalFloat(value *string) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(*value), 64)
+	if err != nil {
+		return nil
+	}
+
+	return &number
 }
@@ -7,21 +7,29 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 func TestWeatherByAddressRepository_Success(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Cidade,Estado,Brazil" {
+			t.Errorf("Path mismatch: expected %v, got %v", "/Cidade,Estado,Brazil", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("format") != "j1" {
+			t.Errorf("Query format mismatch: expected %v, got %v", "j1", r.URL.Query().Get("format"))
+		}
+
 		responseBody := `{"current_condition":[{"temp_C":"30"}]}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByAddressRepository(server.URL)
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -32,7 +40,7 @@ func TestWeatherByAddressRepository_Success(t *testing.T) {
 		State:      "Estado",
 	}
 
-	temperature, err := repo.GetWeather(address, context.Background(), context.Background())
+	temperature, err := repo.GetWeather(address, context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -47,14 +55,12 @@ func TestWeatherByAddressRepository_Success(t *testing.T) {
 }
 
 func TestWeatherByAddressRepository_ErrorHttp(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "", http.StatusMovedPermanently)
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByAddressRepository(server.URL)
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -65,7 +71,7 @@ func TestWeatherByAddressRepository_ErrorHttp(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetWeather(address, context.Background(), context.Background())
+	_, err := repo.GetWeather(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -77,8 +83,6 @@ func TestWeatherByAddressRepository_ErrorHttp(t *testing.T) {
 }
 
 func TestWeatherByAddressRepository_NotStatusOK(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	statusServerError := http.StatusInternalServerError
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -86,7 +90,7 @@ func TestWeatherByAddressRepository_NotStatusOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByAddressRepository(server.URL)
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -97,7 +101,7 @@ func TestWeatherByAddressRepository_NotStatusOK(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetWeather(address, context.Background(), context.Background())
+	_, err := repo.GetWeather(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -109,15 +113,13 @@ func TestWeatherByAddressRepository_NotStatusOK(t *testing.T) {
 }
 
 func TestWeatherByAddressRepository_ErrorJsonDecoder(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `error`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByAddressRepository(server.URL)
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -128,7 +130,7 @@ func TestWeatherByAddressRepository_ErrorJsonDecoder(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetWeather(address, context.Background(), context.Background())
+	_, err := repo.GetWeather(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -140,15 +142,13 @@ func TestWeatherByAddressRepository_ErrorJsonDecoder(t *testing.T) {
 }
 
 func TestWeatherByAddressRepository_JsonBlank(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `{"current_condition":[]}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByAddressRepository(server.URL)
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	address := &model.Address{
 		PostalCode: "12345-678",
@@ -159,7 +159,7 @@ func TestWeatherByAddressRepository_JsonBlank(t *testing.T) {
 		State:      "Estado",
 	}
 
-	_, err := repo.GetWeather(address, context.Background(), context.Background())
+	_, err := repo.GetWeather(address, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -169,3 +169,91 @@ func TestWeatherByAddressRepository_JsonBlank(t *testing.T) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
 }
+
+func TestWeatherByAddressRepository_Temperatures(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		expected    float64
+		expectedErr string
+	}{
+		{name: "zero", body: `{"current_condition":[{"temp_C":"0"}]}`, expected: 0},
+		{name: "sub-zero", body: `{"current_condition":[{"temp_C":"-2"}]}`, expected: -2},
+		{name: "missing", body: `{"current_condition":[{}]}`, expectedErr: "temp_C is missing"},
+		{name: "not a number", body: `{"current_condition":[{"temp_C":"N/A"}]}`, expectedErr: `invalid temp_C "N/A"`},
+		{name: "out of range", body: `{"current_condition":[{"temp_C":"99"}]}`, expectedErr: "out of the plausible range"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+			weather, err := repo.GetWeather(&model.Address{City: "Urupema", State: "SC"}, context.Background())
+
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if weather.Temperature != test.expected {
+				t.Errorf("Temperature mismatch: expected %v, got %v", test.expected, weather.Temperature)
+			}
+		})
+	}
+}
+
+func TestWeatherByAddressRepository_Conditions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseBody := `{"current_condition":[{"temp_C":"18","FeelsLikeC":"17","humidity":"77","windspeedKmph":"9",` +
+			`"winddirDegree":"270","weatherCode":"296"}]}`
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	weather, err := repo.GetWeather(&model.Address{City: "Cidade", State: "Estado"}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if *weather.ApparentTemperature != 17 || *weather.Humidity != 77 || *weather.WindSpeed != 9 || *weather.WindDirection != 270 {
+		t.Errorf("Unexpected conditions %+v", weather)
+	}
+
+	// 296 is the light rain code of wttr.in, mapped to the WMO slight rain.
+	if weather.WeatherCode == nil || *weather.WeatherCode != 61 {
+		t.Errorf("Expected weather code 61, got %v", weather.WeatherCode)
+	}
+}
+
+func TestWeatherByAddressRepository_MalformedOptionalFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseBody := `{"current_condition":[{"temp_C":"18","humidity":"","weatherCode":"1"}]}`
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewWeatherByAddressRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	weather, err := repo.GetWeather(&model.Address{City: "Cidade", State: "Estado"}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if weather.Humidity != nil || weather.WeatherCode != nil || weather.ApparentTemperature != nil {
+		t.Errorf("Expected the malformed and missing fields to be nil, got %+v", weather)
+	}
+}
@@ -4,69 +4,113 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
 	"go.opentelemetry.io/otel"
 )
 
 type WeatherByCoordinatesRepository interface {
-	GetWeather(*model.Coordinates, context.Context, context.Context) (*model.Weather, error)
+	GetWeather(*model.Coordinates, context.Context) (*model.Weather, error)
 }
 
 type weatherByCoordinatesRepository struct {
-	URL string
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	logger  *slog.Logger
 }
 
-func NewWeatherByCoordinatesRepository(url string) WeatherByCoordinatesRepository {
+func NewWeatherByCoordinatesRepository(baseURL string, client *http.Client, timeout time.Duration, logger *slog.Logger) WeatherByCoordinatesRepository {
 	return &weatherByCoordinatesRepository{
-		URL: url,
+		baseURL: baseURL,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
 	}
 }
 
-func (r *weatherByCoordinatesRepository) GetWeather(coordinates *model.Coordinates, ctx context.Context, ctxDistributed context.Context) (*model.Weather, error) {
+func (r *weatherByCoordinatesRepository) GetWeather(coordinates *model.Coordinates, ctx context.Context) (_ *model.Weather, err error) {
 	tracer := otel.Tracer("WeatherByCoordinatesRepository")
 
-	_, span := tracer.Start(ctx, "WeatherByCoordinatesRepository.GetWeather")
-	defer span.End()
+	ctx, span := tracer.Start(ctx, "WeatherByCoordinatesRepository.GetWeather")
+	defer telemetry.EndSpan(span, &err)
+	defer telemetry.RecordUpstreamCall("open-meteo", time.Now(), &err, ctx)
+	defer logging.Error(r.logger, "open-meteo request failed", &err, ctx, "latitude", coordinates.Latitude, "longitude", coordinates.Longitude)
 
-	_, spanDistributed := tracer.Start(ctxDistributed, "WeatherByCoordinatesRepository.GetWeather")
-	defer spanDistributed.End()
+	params := url.Values{}
+	params.Add("latitude", coordinates.Latitude)
+	params.Add("longitude", coordinates.Longitude)
+	params.Add("current_weather", "true")
+	params.Add("current", "relative_humidity_2m,apparent_temperature")
 
-	var url string
+	weatherURL, err := utils.BuildURL(r.baseURL, []string{"v1", "forecast"}, params)
+	if err != nil {
+		return nil, fmt.Errorf("error when building weather api url: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
 
-	if os.Getenv("TEST") == "true" {
-		url = r.URL
-	} else {
-		url = fmt.Sprintf(r.URL, coordinates.Latitude, coordinates.Longitude)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, weatherURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating request: %w", err)
 	}
 
-	resp, err := http.Get(url)
+	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error when searching for weather forecast: %w", err)
+		return nil, apperrors.Transport("open-meteo", err, "error when searching for weather forecast")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("weather api returned status %d", resp.StatusCode)
+		return nil, apperrors.Status("open-meteo", resp.StatusCode, "weather api returned status %d", resp.StatusCode)
 	}
 
+	// The temperature is decoded as a pointer, so a missing field is told
+	// apart from a legitimate 0°C.
 	var tempWeather struct {
 		CurrentWeather struct {
-			Temperature float64 `json:"temperature"`
+			Temperature   *float64 `json:"temperature"`
+			WindSpeed     *float64 `json:"windspeed"`
+			WindDirection *float64 `json:"winddirection"`
+			WeatherCode   *int     `json:"weathercode"`
 		} `json:"current_weather"`
+		Current struct {
+			Humidity            *float64 `json:"relative_humidity_2m"`
+			ApparentTemperature *float64 `json:"apparent_temperature"`
+		} `json:"current"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&tempWeather); err != nil {
-		return nil, fmt.Errorf("error parsing json: %w", err)
+		return nil, apperrors.BadPayload("open-meteo", err, "error parsing json")
 	}
 
-	if tempWeather.CurrentWeather.Temperature > 0 {
-		weather := &model.Weather{Temperature: tempWeather.CurrentWeather.Temperature}
+	if tempWeather.CurrentWeather.Temperature == nil {
+		return nil, apperrors.BadPayload("open-meteo", nil, "temperature error: current_weather.temperature is missing")
+	}
 
-		return weather, nil
-	} else {
-		return nil, fmt.Errorf("temperature error")
+	temperature := *tempWeather.CurrentWeather.Temperature
+
+	if err := validateTemperature("open-meteo", temperature); err != nil {
+		return nil, err
+	}
+
+	weather := &model.Weather{
+		Temperature:         temperature,
+		ApparentTemperature: tempWeather.Current.ApparentTemperature,
+		Humidity:            tempWeather.Current.Humidity,
+		WindSpeed:           tempWeather.CurrentWeather.WindSpeed,
+		WindDirection:       tempWeather.CurrentWeather.WindDirection,
+		WeatherCode:         tempWeather.CurrentWeather.WeatherCode,
 	}
+
+	return weather, nil
 }
@@ -7,28 +7,44 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 func TestWeatherByCoordinatesRepository_Success(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/forecast" {
+			t.Errorf("Path mismatch: expected %v, got %v", "/v1/forecast", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("latitude") != "123" {
+			t.Errorf("Query latitude mismatch: expected %v, got %v", "123", r.URL.Query().Get("latitude"))
+		}
+
+		if r.URL.Query().Get("longitude") != "321" {
+			t.Errorf("Query longitude mismatch: expected %v, got %v", "321", r.URL.Query().Get("longitude"))
+		}
+
+		if r.URL.Query().Get("current_weather") != "true" {
+			t.Errorf("Query current_weather mismatch: expected %v, got %v", "true", r.URL.Query().Get("current_weather"))
+		}
+
 		responseBody := `{"current_weather":{"temperature":30.0}}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByCoordinatesRepository(server.URL)
+	repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	coordinates := &model.Coordinates{
 		Latitude:  "123",
 		Longitude: "321",
 	}
 
-	temperature, err := repo.GetWeather(coordinates, context.Background(), context.Background())
+	temperature, err := repo.GetWeather(coordinates, context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -43,21 +59,19 @@ func TestWeatherByCoordinatesRepository_Success(t *testing.T) {
 }
 
 func TestWeatherByCoordinatesRepository_ErrorHttp(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "", http.StatusMovedPermanently)
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByCoordinatesRepository(server.URL)
+	repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	coordinates := &model.Coordinates{
 		Latitude:  "123",
 		Longitude: "321",
 	}
 
-	_, err := repo.GetWeather(coordinates, context.Background(), context.Background())
+	_, err := repo.GetWeather(coordinates, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -69,8 +83,6 @@ func TestWeatherByCoordinatesRepository_ErrorHttp(t *testing.T) {
 }
 
 func TestWeatherByCoordinatesRepository_NotStatusOK(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	statusServerError := http.StatusInternalServerError
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -78,14 +90,14 @@ func TestWeatherByCoordinatesRepository_NotStatusOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByCoordinatesRepository(server.URL)
+	repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	coordinates := &model.Coordinates{
 		Latitude:  "123",
 		Longitude: "321",
 	}
 
-	_, err := repo.GetWeather(coordinates, context.Background(), context.Background())
+	_, err := repo.GetWeather(coordinates, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -97,22 +109,20 @@ func TestWeatherByCoordinatesRepository_NotStatusOK(t *testing.T) {
 }
 
 func TestWeatherByCoordinatesRepository_ErrorJsonDecoder(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseBody := `error`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByCoordinatesRepository(server.URL)
+	repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	coordinates := &model.Coordinates{
 		Latitude:  "123",
 		Longitude: "321",
 	}
 
-	_, err := repo.GetWeather(coordinates, context.Background(), context.Background())
+	_, err := repo.GetWeather(coordinates, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -124,22 +134,20 @@ func TestWeatherByCoordinatesRepository_ErrorJsonDecoder(t *testing.T) {
 }
 
 func TestWeatherByCoordinatesRepository_JsonBlank(t *testing.T) {
-	t.Setenv("TEST", "true")
-
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		responseBody := `{"current_weather":{"temperature":0.0}}`
+		responseBody := `{"current_weather":{}}`
 		w.Write([]byte(responseBody))
 	}))
 	defer server.Close()
 
-	repo := repository.NewWeatherByCoordinatesRepository(server.URL)
+	repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
 
 	coordinates := &model.Coordinates{
 		Latitude:  "123",
 		Longitude: "321",
 	}
 
-	_, err := repo.GetWeather(coordinates, context.Background(), context.Background())
+	_, err := repo.GetWeather(coordinates, context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -149,3 +157,74 @@ func TestWeatherByCoordinatesRepository_JsonBlank(t *testing.T) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
 }
+
+func TestWeatherByCoordinatesRepository_Temperatures(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature string
+		expected    float64
+		expectedErr string
+	}{
+		{name: "zero", temperature: "0", expected: 0},
+		{name: "sub-zero", temperature: "-3.4", expected: -3.4},
+		{name: "too cold", temperature: "-120", expectedErr: "out of the plausible range"},
+		{name: "too hot", temperature: "75", expectedErr: "out of the plausible range"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"current_weather":{"temperature":%s}}`, test.temperature)
+			}))
+			defer server.Close()
+
+			repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+			weather, err := repo.GetWeather(&model.Coordinates{Latitude: "-28.28", Longitude: "-49.93"}, context.Background())
+
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("Expected error containing %q, got %v", test.expectedErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if weather.Temperature != test.expected {
+				t.Errorf("Temperature mismatch: expected %v, got %v", test.expected, weather.Temperature)
+			}
+		})
+	}
+}
+
+func TestWeatherByCoordinatesRepository_Conditions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("current") != "relative_humidity_2m,apparent_temperature" {
+			t.Errorf("Query current mismatch: got %v", r.URL.Query().Get("current"))
+		}
+
+		responseBody := `{"current_weather":{"temperature":12.5,"windspeed":14.2,"winddirection":45,"weathercode":61},` +
+			`"current":{"relative_humidity_2m":93,"apparent_temperature":10.1}}`
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	repo := repository.NewWeatherByCoordinatesRepository(server.URL, server.Client(), time.Second, logging.Discard())
+
+	weather, err := repo.GetWeather(&model.Coordinates{Latitude: "123", Longitude: "321"}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if *weather.WindSpeed != 14.2 || *weather.WindDirection != 45 || *weather.WeatherCode != 61 {
+		t.Errorf("Unexpected wind or weather code %v %v %v", *weather.WindSpeed, *weather.WindDirection, *weather.WeatherCode)
+	}
+
+	if *weather.Humidity != 93 || *weather.ApparentTemperature != 10.1 {
+		t.Errorf("Unexpected humidity or apparent temperature %v %v", *weather.Humidity, *weather.ApparentTemperature)
+	}
+}
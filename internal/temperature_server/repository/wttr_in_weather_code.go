@@ -0,0 +1,63 @@
+package repository
+
+// wttrInWeatherCodes maps the WorldWeatherOnline codes answered by wttr.in to
+// the closest WMO weather code, the one used by open-meteo.
+var wttrInWeatherCodes = map[int]int{
+	113: 0,  // Sunny
+	116: 2,  // Partly cloudy
+	119: 3,  // Cloudy
+	122: 3,  // Overcast
+	143: 45, // Mist
+	176: 80, // Patchy rain possible
+	179: 85, // Patchy snow possible
+	182: 66, // Patchy sleet possible
+	185: 56, // Patchy freezing drizzle possible
+	200: 95, // Thundery outbreaks possible
+	227: 73, // Blowing snow
+	230: 75, // Blizzard
+	248: 45, // Fog
+	260: 48, // Freezing fog
+	263: 51, // Patchy light drizzle
+	266: 51, // Light drizzle
+	281: 56, // Freezing drizzle
+	284: 57, // Heavy freezing drizzle
+	293: 61, // Patchy light rain
+	296: 61, // Light rain
+	299: 63, // Moderate rain at times
+	302: 63, // Moderate rain
+	305: 65, // Heavy rain at times
+	308: 65, // Heavy rain
+	311: 66, // Light freezing rain
+	314: 67, // Moderate or heavy freezing rain
+	317: 66, // Light sleet
+	320: 67, // Moderate or heavy sleet
+	323: 71, // Patchy light snow
+	326: 71, // Light snow
+	329: 73, // Patchy moderate snow
+	332: 73, // Moderate snow
+	335: 75, // Patchy heavy snow
+	338: 75, // Heavy snow
+	350: 77, // Ice pellets
+	353: 80, // Light rain shower
+	356: 81, // Moderate or heavy rain shower
+	359: 82, // Torrential rain shower
+	362: 85, // Light sleet showers
+	365: 86, // Moderate or heavy sleet showers
+	368: 85, // Light snow showers
+	371: 86, // Moderate or heavy snow showers
+	374: 85, // Light showers of ice pellets
+	377: 86, // Moderate or heavy showers of ice pellets
+	386: 95, // Patchy light rain with thunder
+	389: 95, // Moderate or heavy rain with thunder
+	392: 96, // Patchy light snow with thunder
+	395: 99, // Moderate or heavy snow with thunder
+}
+
+func wttrInWeatherCode(code int) *int {
+	wmo, ok := wttrInWeatherCodes[code]
+	if !ok {
+		return nil
+	}
+
+	return &wmo
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/batch"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type BatchService interface {
+	GetWeatherByCEPs([]string, context.Context) []model.BatchItem
+}
+
+type batchService struct {
+	weatherService WeatherService
+	concurrency    int
+	logger         *slog.Logger
+}
+
+func NewBatchService(weatherService WeatherService, concurrency int, logger *slog.Logger) BatchService {
+	return &batchService{
+		weatherService: weatherService,
+		concurrency:    concurrency,
+		logger:         logger,
+	}
+}
+
+// GetWeatherByCEPs looks each distinct zipcode up once, at most concurrency
+// at a time, and answers one item per zipcode in the order they were given.
+// Zipcodes are told apart by their canonical form, so "01001-000" and
+// "01001000" share one lookup. A failed zipcode does not fail the batch.
+func (s *batchService) GetWeatherByCEPs(ceps []string, ctx context.Context) []model.BatchItem {
+	tracer := otel.Tracer("BatchService")
+
+	ctx, span := tracer.Start(ctx, "BatchService.GetWeatherByCEPs")
+	defer span.End()
+
+	results := batch.Map(canonical(ceps), s.concurrency, s.getWeather, ctx)
+
+	items := make([]model.BatchItem, len(ceps))
+	failed := 0
+
+	for i, result := range results {
+		items[i] = model.NewBatchItem(ceps[i], result.Value, result.Err)
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("batch.size", len(ceps)), attribute.Int("batch.failed", failed))
+
+	s.logger.DebugContext(ctx, "batch done", "size", len(ceps), "failed", failed)
+
+	return items
+}
+
+func (s *batchService) getWeather(cep string, ctx context.Context) (_ *model.Temperature, err error) {
+	tracer := otel.Tracer("BatchService")
+
+	ctx, span := tracer.Start(ctx, "BatchService.GetWeatherByCEP")
+	defer telemetry.EndSpan(span, &err)
+
+	span.SetAttributes(telemetry.CEPAttribute(cep))
+
+	return s.weatherService.GetWeatherByCEP(cep, ctx)
+}
+
+// canonical keeps the zipcodes that do not parse as they are, so that they
+// still fail with their own error.
+func canonical(ceps []string) []string {
+	keys := make([]string, len(ceps))
+
+	for i, value := range ceps {
+		keys[i] = value
+		if zipcode, err := cep.Parse(value); err == nil {
+			keys[i] = zipcode.String()
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockWeatherService struct {
+	mu    sync.Mutex
+	Calls map[string]int
+}
+
+func (m *MockWeatherService) GetWeatherByCEP(cep string, ctx context.Context) (*model.Temperature, error) {
+	m.mu.Lock()
+	m.Calls[cep]++
+	m.mu.Unlock()
+
+	if cep == "00000000" {
+		return nil, apperrors.ErrCEPNotFound
+	}
+
+	if cep == "99999999" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return &model.Temperature{City: "City " + cep, Celsius: 20}, nil
+}
+
+func (m *MockWeatherService) GetForecastByCEP(string, model.ForecastQuery, context.Context) (*model.Forecast, error) {
+	return nil, nil
+}
+
+func TestBatchService_GetWeatherByCEPs(t *testing.T) {
+	mockService := &MockWeatherService{Calls: map[string]int{}}
+
+	batchService := service.NewBatchService(mockService, 2, logging.Discard())
+
+	items := batchService.GetWeatherByCEPs([]string{"01001000", "00000000", "88625000", "01001-000"}, context.Background())
+
+	expected := []struct {
+		cep    string
+		status int
+		city   string
+	}{
+		{"01001000", http.StatusOK, "City 01001000"},
+		{"00000000", http.StatusNotFound, ""},
+		{"88625000", http.StatusOK, "City 88625000"},
+		{"01001-000", http.StatusOK, "City 01001000"},
+	}
+
+	if len(items) != len(expected) {
+		t.Fatalf("Expected %d items, got %d", len(expected), len(items))
+	}
+
+	for i, item := range items {
+		if item.CEP != expected[i].cep || item.Status != expected[i].status {
+			t.Errorf("Item %d: expected %s %d, got %s %d", i, expected[i].cep, expected[i].status, item.CEP, item.Status)
+		}
+
+		if expected[i].city != "" && (item.Temperature == nil || item.Temperature.City != expected[i].city) {
+			t.Errorf("Item %d: expected the temperature of %s, got %+v", i, expected[i].city, item.Temperature)
+		}
+	}
+
+	if items[1].Error != "can not find zipcode" {
+		t.Errorf("Expected the not found message, got %q", items[1].Error)
+	}
+
+	if mockService.Calls["01001000"] != 1 {
+		t.Errorf("Expected the duplicated zipcode to be looked up once, got %d", mockService.Calls["01001000"])
+	}
+}
+
+func TestBatchService_GetWeatherByCEPs_Deadline(t *testing.T) {
+	mockService := &MockWeatherService{Calls: map[string]int{}}
+
+	batchService := service.NewBatchService(mockService, 1, logging.Discard())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	items := batchService.GetWeatherByCEPs([]string{"01001000", "99999999", "88625000"}, ctx)
+
+	for i, expected := range []int{http.StatusOK, http.StatusGatewayTimeout, http.StatusGatewayTimeout} {
+		if items[i].Status != expected {
+			t.Errorf("Item %d: expected status %d, got %d", i, expected, items[i].Status)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+)
+
+// ForecastProvider answers the forecast for a resolved location, following
+// the same rules as WeatherProvider.
+type ForecastProvider interface {
+	Name() string
+	GetForecast(*model.Address, *model.Coordinates, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error)
+}
+
+type forecastByCoordinatesProvider struct {
+	name       string
+	repository repository.ForecastByCoordinatesRepository
+}
+
+func NewForecastByCoordinatesProvider(name string, repository repository.ForecastByCoordinatesRepository) ForecastProvider {
+	return &forecastByCoordinatesProvider{
+		name:       name,
+		repository: repository,
+	}
+}
+
+func (p *forecastByCoordinatesProvider) Name() string {
+	return p.name
+}
+
+func (p *forecastByCoordinatesProvider) GetForecast(address *model.Address, coordinates *model.Coordinates, query model.ForecastQuery, ctx context.Context) ([]model.ForecastPoint, error) {
+	if coordinates == nil {
+		return nil, fmt.Errorf("coordinates are not available")
+	}
+
+	return p.repository.GetForecast(coordinates, query, ctx)
+}
+
+type forecastByAddressProvider struct {
+	name       string
+	repository repository.ForecastByAddressRepository
+}
+
+func NewForecastByAddressProvider(name string, repository repository.ForecastByAddressRepository) ForecastProvider {
+	return &forecastByAddressProvider{
+		name:       name,
+		repository: repository,
+	}
+}
+
+func (p *forecastByAddressProvider) Name() string {
+	return p.name
+}
+
+func (p *forecastByAddressProvider) GetForecast(address *model.Address, coordinates *model.Coordinates, query model.ForecastQuery, ctx context.Context) ([]model.ForecastPoint, error) {
+	return p.repository.GetForecast(address, query, ctx)
+}
+
+// NewForecastProviderChain orders the forecast providers by the same names as
+// the weather providers, so both fall back in the same order.
+func NewForecastProviderChain(names []string, available ...ForecastProvider) ([]ForecastProvider, error) {
+	return providerChain(names, available)
+}
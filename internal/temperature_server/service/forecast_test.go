@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockForecastByAddressRepository struct {
+	Points []model.ForecastPoint
+	Err    error
+}
+
+func (m *MockForecastByAddressRepository) GetForecast(*model.Address, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error) {
+	return m.Points, m.Err
+}
+
+type MockForecastByCoordinatesRepository struct {
+	Points []model.ForecastPoint
+	Err    error
+}
+
+func (m *MockForecastByCoordinatesRepository) GetForecast(*model.Coordinates, model.ForecastQuery, context.Context) ([]model.ForecastPoint, error) {
+	return m.Points, m.Err
+}
+
+func newForecastService(coordinatesRepo *MockCoordinatesRepository, forecastByAddressRepo *MockForecastByAddressRepository, forecastByCoordinatesRepo *MockForecastByCoordinatesRepository) service.WeatherService {
+	forecastProviders := []service.ForecastProvider{
+		service.NewForecastByCoordinatesProvider("open-meteo", forecastByCoordinatesRepo),
+		service.NewForecastByAddressProvider("wttr.in", forecastByAddressRepo),
+	}
+
+	addressRepo := &MockAddressRepository{Address: &model.Address{City: "Cidade", State: "Estado"}}
+
+	return service.NewWeatherService(addressRepo, coordinatesRepo, nil, forecastProviders, logging.Discard())
+}
+
+func TestWeatherService_GetForecastByCEP(t *testing.T) {
+	maxCelsius := 25.0
+
+	forecastByCoordinatesRepo := &MockForecastByCoordinatesRepository{Points: []model.ForecastPoint{{Time: "2024-08-30", MaxCelsius: &maxCelsius}}}
+	forecastByAddressRepo := &MockForecastByAddressRepository{Err: errors.New("must not be called")}
+
+	service := newForecastService(&MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "1", Longitude: "2"}}, forecastByAddressRepo, forecastByCoordinatesRepo)
+
+	forecast, err := service.GetForecastByCEP("12345678", model.ForecastQuery{Days: 1, Granularity: model.GranularityDaily}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if forecast.City != "Cidade" || forecast.Granularity != model.GranularityDaily || len(forecast.Points) != 1 {
+		t.Errorf("Unexpected forecast %+v", forecast)
+	}
+}
+
+func TestWeatherService_GetForecastByCEPFallsBackWithoutCoordinates(t *testing.T) {
+	forecastByCoordinatesRepo := &MockForecastByCoordinatesRepository{Err: errors.New("must not be called")}
+	forecastByAddressRepo := &MockForecastByAddressRepository{Points: []model.ForecastPoint{{Time: "2024-08-30"}}}
+
+	service := newForecastService(&MockCoordinatesRepository{Err: errors.New("not found")}, forecastByAddressRepo, forecastByCoordinatesRepo)
+
+	forecast, err := service.GetForecastByCEP("12345678", model.ForecastQuery{Days: 1, Granularity: model.GranularityDaily}, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(forecast.Points) != 1 {
+		t.Errorf("Expected the wttr.in forecast, got %+v", forecast)
+	}
+}
+
+func TestWeatherService_GetForecastByCEPAllProvidersFail(t *testing.T) {
+	forecastByCoordinatesRepo := &MockForecastByCoordinatesRepository{Err: errors.New("open-meteo is down")}
+	forecastByAddressRepo := &MockForecastByAddressRepository{Err: errors.New("wttr.in is down")}
+
+	service := newForecastService(&MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "1", Longitude: "2"}}, forecastByAddressRepo, forecastByCoordinatesRepo)
+
+	_, err := service.GetForecastByCEP("12345678", model.ForecastQuery{Days: 1, Granularity: model.GranularityHourly}, context.Background())
+	if err == nil {
+		t.Fatal("Expected an error but got nil")
+	}
+
+	for _, expected := range []string{"open-meteo is down", "wttr.in is down"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %q", expected, err.Error())
+		}
+	}
+}
+
+func TestWeatherService_GetForecastByCEPUnsupportedQuery(t *testing.T) {
+	daysError := fmt.Errorf("%w: wttr.in forecasts at most 3 days, got 5", apperrors.ErrInvalidForecast)
+
+	tests := []struct {
+		name           string
+		coordinatesErr error
+		expectedStatus int
+	}{
+		{"only wttr.in is left", errors.New("not found"), http.StatusBadRequest},
+		{"open-meteo failed too", nil, http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecastByCoordinatesRepo := &MockForecastByCoordinatesRepository{Err: apperrors.ErrTimeout}
+			forecastByAddressRepo := &MockForecastByAddressRepository{Err: daysError}
+			coordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "1", Longitude: "2"}, Err: tt.coordinatesErr}
+
+			service := newForecastService(coordinatesRepo, forecastByAddressRepo, forecastByCoordinatesRepo)
+
+			_, err := service.GetForecastByCEP("12345678", model.ForecastQuery{Days: 5, Granularity: model.GranularityDaily}, context.Background())
+			if status := apperrors.HTTPStatus(err); status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d for %v", tt.expectedStatus, status, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type HistoryService interface {
+	GetHistoryByCEP(string, model.HistoryQuery, context.Context) (*model.History, error)
+}
+
+type historyService struct {
+	addressRepository     repository.AddressRepository
+	coordinatesRepository repository.CoordinatesRepository
+	historyRepository     repository.HistoryRepository
+	logger                *slog.Logger
+}
+
+func NewHistoryService(
+	addressRepository repository.AddressRepository,
+	coordinatesRepository repository.CoordinatesRepository,
+	historyRepository repository.HistoryRepository,
+	logger *slog.Logger,
+) HistoryService {
+	return &historyService{
+		addressRepository:     addressRepository,
+		coordinatesRepository: coordinatesRepository,
+		historyRepository:     historyRepository,
+		logger:                logger,
+	}
+}
+
+// GetHistoryByCEP needs the coordinates, as the archive is only queried by
+// them, so a failed geocoding fails the request.
+func (s *historyService) GetHistoryByCEP(zipcode string, query model.HistoryQuery, ctx context.Context) (_ *model.History, err error) {
+	tracer := otel.Tracer("HistoryService")
+
+	ctx, span := tracer.Start(ctx, "HistoryService.GetHistoryByCEP")
+	defer telemetry.EndSpan(span, &err)
+
+	span.SetAttributes(attribute.Int("history.days", query.Days()))
+
+	parsed, err := cep.Parse(zipcode)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := s.addressRepository.GetAddress(parsed.String(), ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting address for zipcode %s: %w", parsed, err)
+	}
+
+	coordinates, err := s.coordinatesRepository.GetCoordinates(address, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting coordinates for %s, %s: %w", address.City, address.State, err)
+	}
+
+	days, err := s.historyRepository.GetHistory(coordinates, query, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting history for zipcode %s: %w", parsed, err)
+	}
+
+	s.logger.DebugContext(ctx, "history found", "cep", parsed, "city", address.City, "days", len(days))
+
+	history := &model.History{
+		City:  address.City,
+		Start: query.Start.Format(model.DateLayout),
+		End:   query.End.Format(model.DateLayout),
+		Days:  days,
+	}
+
+	return history, nil
+}
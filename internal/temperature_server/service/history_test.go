@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+type MockHistoryRepository struct {
+	Days []model.HistoryDay
+	Err  error
+}
+
+func (m *MockHistoryRepository) GetHistory(*model.Coordinates, model.HistoryQuery, context.Context) ([]model.HistoryDay, error) {
+	return m.Days, m.Err
+}
+
+var historyQuery = model.HistoryQuery{
+	Start: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC),
+	End:   time.Date(2024, time.July, 3, 0, 0, 0, 0, time.UTC),
+}
+
+func TestHistoryService_GetHistoryByCEP(t *testing.T) {
+	mockAddressRepo := &MockAddressRepository{Address: &model.Address{City: "Urupema", State: "SC"}}
+	mockCoordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "-28.28", Longitude: "-49.93"}}
+	mockHistoryRepo := &MockHistoryRepository{Days: []model.HistoryDay{{Date: "2024-07-01"}, {Date: "2024-07-02"}, {Date: "2024-07-03"}}}
+
+	service := service.NewHistoryService(mockAddressRepo, mockCoordinatesRepo, mockHistoryRepo, logging.Discard())
+
+	history, err := service.GetHistoryByCEP("88625000", historyQuery, context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if history.City != "Urupema" || history.Start != "2024-07-01" || history.End != "2024-07-03" || len(history.Days) != 3 {
+		t.Errorf("Unexpected history %+v", history)
+	}
+}
+
+func TestHistoryService_CoordinatesNotFound(t *testing.T) {
+	mockAddressRepo := &MockAddressRepository{Address: &model.Address{City: "Urupema", State: "SC"}}
+	mockCoordinatesRepo := &MockCoordinatesRepository{Err: errors.New("nominatim is down")}
+	mockHistoryRepo := &MockHistoryRepository{Err: errors.New("must not be called")}
+
+	service := service.NewHistoryService(mockAddressRepo, mockCoordinatesRepo, mockHistoryRepo, logging.Discard())
+
+	_, err := service.GetHistoryByCEP("88625000", historyQuery, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "nominatim is down") {
+		t.Errorf("Expected the coordinates error, got %v", err)
+	}
+}
@@ -2,73 +2,238 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/coalesce"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
 	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type WeatherService interface {
-	GetWeatherByCEP(string, context.Context, context.Context) (*model.Temperature, error)
+	GetWeatherByCEP(string, context.Context) (*model.Temperature, error)
+	GetForecastByCEP(string, model.ForecastQuery, context.Context) (*model.Forecast, error)
 }
 
 type weatherService struct {
-	addressRepository              repository.AddressRepository
-	coordinatesRepository          repository.CoordinatesRepository
-	weatherByAddressRepository     repository.WeatherByAddressRepository
-	weatherByCoordinatesRepository repository.WeatherByCoordinatesRepository
+	addressRepository     repository.AddressRepository
+	coordinatesRepository repository.CoordinatesRepository
+	weatherProviders      []WeatherProvider
+	forecastProviders     []ForecastProvider
+	inFlight              *coalesce.Group[string, *model.Temperature]
+	logger                *slog.Logger
 }
 
 func NewWeatherService(
 	addressRepository repository.AddressRepository,
 	coordinatesRepository repository.CoordinatesRepository,
-	weatherByAddressRepository repository.WeatherByAddressRepository,
-	weatherByCoordinatesRepository repository.WeatherByCoordinatesRepository,
+	weatherProviders []WeatherProvider,
+	forecastProviders []ForecastProvider,
+	logger *slog.Logger,
 ) WeatherService {
 	return &weatherService{
-		addressRepository:              addressRepository,
-		coordinatesRepository:          coordinatesRepository,
-		weatherByAddressRepository:     weatherByAddressRepository,
-		weatherByCoordinatesRepository: weatherByCoordinatesRepository,
+		addressRepository:     addressRepository,
+		coordinatesRepository: coordinatesRepository,
+		weatherProviders:      weatherProviders,
+		forecastProviders:     forecastProviders,
+		inFlight:              coalesce.New[string, *model.Temperature](),
+		logger:                logger,
 	}
 }
 
-func (s *weatherService) GetWeatherByCEP(cep string, ctx context.Context, ctxDistributed context.Context) (*model.Temperature, error) {
+// GetWeatherByCEP shares the lookup of a zipcode already in flight, linking
+// the span of every waiter to the one of the request that made it.
+func (s *weatherService) GetWeatherByCEP(zipcode string, ctx context.Context) (_ *model.Temperature, err error) {
 	tracer := otel.Tracer("WeatherService")
 
 	ctx, span := tracer.Start(ctx, "WeatherService.GetWeatherByCEP")
-	defer span.End()
+	defer telemetry.EndSpan(span, &err)
 
-	ctxDistributed, spanDistributed := tracer.Start(ctxDistributed, "WeatherService.GetWeatherByCEP")
-	defer spanDistributed.End()
+	parsed, err := cep.Parse(zipcode)
+	if err != nil {
+		return nil, err
+	}
+
+	temperature, leader, err := s.inFlight.Do(parsed.String(), func(ctx context.Context) (*model.Temperature, error) {
+		return s.getWeatherByCEP(parsed, ctx)
+	}, ctx)
+
+	if leader.IsValid() {
+		span.AddLink(trace.Link{SpanContext: leader})
+	}
+
+	span.SetAttributes(attribute.Bool("coalesce.shared", leader.IsValid()))
+	telemetry.RecordCoalescedCall("weather_by_cep", leader.IsValid(), ctx)
+
+	return temperature, err
+}
+
+func (s *weatherService) getWeatherByCEP(zipcode cep.CEP, ctx context.Context) (*model.Temperature, error) {
+	span := trace.SpanFromContext(ctx)
+
+	address, coordinates, err := s.resolveLocation(zipcode, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	weather, err := s.getWeather(address, coordinates, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("weather.provider", weather.Provider))
+	s.logger.DebugContext(ctx, "weather found", "cep", zipcode, "city", address.City, "provider", weather.Provider)
 
-	address, err := s.addressRepository.GetAddress(cep, ctx, ctxDistributed)
+	return newTemperature(zipcode, address, weather), nil
+}
+
+func (s *weatherService) GetForecastByCEP(zipcode string, query model.ForecastQuery, ctx context.Context) (_ *model.Forecast, err error) {
+	tracer := otel.Tracer("WeatherService")
+
+	ctx, span := tracer.Start(ctx, "WeatherService.GetForecastByCEP")
+	defer telemetry.EndSpan(span, &err)
+
+	span.SetAttributes(
+		attribute.Int("forecast.days", query.Days),
+		attribute.String("forecast.granularity", query.Granularity),
+	)
+
+	parsed, err := cep.Parse(zipcode)
 	if err != nil {
 		return nil, err
 	}
 
-	var weather *model.Weather
+	address, coordinates, err := s.resolveLocation(parsed, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs, unsupported []error
+
+	for _, provider := range s.forecastProviders {
+		points, err := provider.GetForecast(address, coordinates, query, ctx)
+		if err == nil {
+			span.SetAttributes(attribute.String("forecast.provider", provider.Name()))
+			s.logger.DebugContext(ctx, "forecast found", "cep", parsed, "city", address.City, "provider", provider.Name())
 
-	coordinates, err := s.coordinatesRepository.GetCoordinates(address, ctx, ctxDistributed)
-	if err == nil {
-		weather, err = s.weatherByCoordinatesRepository.GetWeather(coordinates, ctx, ctxDistributed)
-		if err != nil {
-			return nil, err
+			return &model.Forecast{City: address.City, Granularity: query.Granularity, Points: points}, nil
 		}
-	} else {
-		weather, err = s.weatherByAddressRepository.GetWeather(address, ctx, ctxDistributed)
-		if err != nil {
-			return nil, err
+
+		span.RecordError(err, trace.WithAttributes(attribute.String("forecast.provider", provider.Name())))
+		s.logger.WarnContext(ctx, "forecast provider failed", "provider", provider.Name(), "error", err)
+
+		if errors.Is(err, apperrors.ErrInvalidForecast) {
+			unsupported = append(unsupported, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
 		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	// A provider that can not answer the query, such as wttr.in past its
+	// days, only makes it a client error when no provider failed upstream.
+	if !slices.ContainsFunc(errs, isProviderFailure) {
+		errs = append(unsupported, errs...)
+	}
+
+	return nil, fmt.Errorf("error when getting forecast from every provider: %w", errors.Join(errs...))
+}
+
+// isProviderFailure reports whether a provider failed because of its
+// upstream, including the calls its circuit breaker or rate limit refused.
+func isProviderFailure(err error) bool {
+	return apperrors.IsUpstreamFailure(err) ||
+		errors.Is(err, apperrors.ErrCircuitOpen) ||
+		errors.Is(err, apperrors.ErrRateLimited)
+}
+
+// resolveLocation finds the address of the zipcode and its coordinates. The
+// coordinates are nil when the geocoding failed, leaving only the providers
+// that do not need them.
+func (s *weatherService) resolveLocation(zipcode cep.CEP, ctx context.Context) (*model.Address, *model.Coordinates, error) {
+	span := trace.SpanFromContext(ctx)
+
+	address, err := s.addressRepository.GetAddress(zipcode.String(), ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when getting address for zipcode %s: %w", zipcode, err)
 	}
 
+	coordinates, err := s.coordinatesRepository.GetCoordinates(address, ctx)
+	if err != nil {
+		span.RecordError(err)
+		s.logger.WarnContext(ctx, "coordinates not found, using only the providers that do not need them", "city", address.City, "state", address.State, "error", err)
+
+		return address, nil, nil
+	}
+
+	return address, coordinates, nil
+}
+
+func newTemperature(zipcode cep.CEP, address *model.Address, weather *model.Weather) *model.Temperature {
 	temperature := &model.Temperature{
 		City:       address.City,
+		CEP:        zipcode.Formatted(),
+		Address:    address,
 		Celsius:    weather.Temperature,
 		Fahrenheit: utils.CelsiusToFahrenheit(weather.Temperature),
 		Kelvin:     utils.CelsiusToKelvin(weather.Temperature),
+		Humidity:   weather.Humidity,
+	}
+
+	if weather.ApparentTemperature != nil {
+		temperature.FeelsLike = &model.FeelsLike{
+			Celsius:    *weather.ApparentTemperature,
+			Fahrenheit: utils.CelsiusToFahrenheit(*weather.ApparentTemperature),
+			Kelvin:     utils.CelsiusToKelvin(*weather.ApparentTemperature),
+		}
+	}
+
+	if weather.WindSpeed != nil {
+		temperature.Wind = &model.Wind{SpeedKmh: *weather.WindSpeed, Direction: weather.WindDirection}
+
+		if weather.WindDirection != nil {
+			temperature.Wind.Cardinal = model.CardinalDirection(*weather.WindDirection)
+		}
+	}
+
+	if weather.WeatherCode != nil {
+		temperature.Condition = model.NewCondition(*weather.WeatherCode)
+	}
+
+	return temperature
+}
+
+// getWeather tries each provider of the chain in order until one of them
+// answers, recording the reason every skipped provider failed.
+func (s *weatherService) getWeather(address *model.Address, coordinates *model.Coordinates, ctx context.Context) (*model.Weather, error) {
+	span := trace.SpanFromContext(ctx)
+
+	var errs []error
+
+	for i, provider := range s.weatherProviders {
+		weather, err := provider.GetWeather(address, coordinates, ctx)
+		if err == nil {
+			weather.Provider = provider.Name()
+			telemetry.RecordWeatherProvider(provider.Name(), i > 0, ctx)
+
+			return weather, nil
+		}
+
+		providerAttribute := trace.WithAttributes(attribute.String("weather.provider", provider.Name()))
+		span.RecordError(err, providerAttribute)
+		s.logger.WarnContext(ctx, "weather provider failed", "provider", provider.Name(), "error", err)
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
 	}
 
-	return temperature, nil
+	return nil, fmt.Errorf("error when getting weather from every provider: %w", errors.Join(errs...))
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/repository"
+)
+
+// WeatherProvider answers the current weather for a resolved location. The
+// coordinates are nil when the geocoding failed, so providers that depend on
+// them must fail and let the next provider of the chain answer.
+type WeatherProvider interface {
+	Name() string
+	GetWeather(*model.Address, *model.Coordinates, context.Context) (*model.Weather, error)
+}
+
+type weatherByCoordinatesProvider struct {
+	name       string
+	repository repository.WeatherByCoordinatesRepository
+}
+
+func NewWeatherByCoordinatesProvider(name string, repository repository.WeatherByCoordinatesRepository) WeatherProvider {
+	return &weatherByCoordinatesProvider{
+		name:       name,
+		repository: repository,
+	}
+}
+
+func (p *weatherByCoordinatesProvider) Name() string {
+	return p.name
+}
+
+func (p *weatherByCoordinatesProvider) GetWeather(address *model.Address, coordinates *model.Coordinates, ctx context.Context) (*model.Weather, error) {
+	if coordinates == nil {
+		return nil, fmt.Errorf("coordinates are not available")
+	}
+
+	return p.repository.GetWeather(coordinates, ctx)
+}
+
+type weatherByAddressProvider struct {
+	name       string
+	repository repository.WeatherByAddressRepository
+}
+
+func NewWeatherByAddressProvider(name string, repository repository.WeatherByAddressRepository) WeatherProvider {
+	return &weatherByAddressProvider{
+		name:       name,
+		repository: repository,
+	}
+}
+
+func (p *weatherByAddressProvider) Name() string {
+	return p.name
+}
+
+func (p *weatherByAddressProvider) GetWeather(address *model.Address, coordinates *model.Coordinates, ctx context.Context) (*model.Weather, error) {
+	return p.repository.GetWeather(address, ctx)
+}
+
+// NewWeatherProviderChain orders the available providers by name, as listed in
+// the configuration. Providers that are not listed are left out of the chain.
+func NewWeatherProviderChain(names []string, available ...WeatherProvider) ([]WeatherProvider, error) {
+	return providerChain(names, available)
+}
+
+func providerChain[P interface{ Name() string }](names []string, available []P) ([]P, error) {
+	providersByName := make(map[string]P, len(available))
+	for _, provider := range available {
+		providersByName[provider.Name()] = provider
+	}
+
+	chain := make([]P, 0, len(names))
+
+	for _, name := range names {
+		provider, ok := providersByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown weather provider %q", name)
+		}
+
+		chain = append(chain, provider)
+	}
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("at least one weather provider must be configured")
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
+	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+)
+
+func TestNewWeatherProviderChain_Order(t *testing.T) {
+	openMeteo := service.NewWeatherByCoordinatesProvider("open-meteo", &MockWeatherByCoordinatesRepository{})
+	wttrIn := service.NewWeatherByAddressProvider("wttr.in", &MockWeatherByAddressRepository{})
+
+	chain, err := service.NewWeatherProviderChain([]string{"wttr.in", "open-meteo"}, openMeteo, wttrIn)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(chain) != 2 || chain[0].Name() != "wttr.in" || chain[1].Name() != "open-meteo" {
+		t.Errorf("Unexpected chain order: %v", chain)
+	}
+}
+
+func TestNewWeatherProviderChain_UnknownProvider(t *testing.T) {
+	openMeteo := service.NewWeatherByCoordinatesProvider("open-meteo", &MockWeatherByCoordinatesRepository{})
+
+	_, err := service.NewWeatherProviderChain([]string{"open-meteo", "unknown"}, openMeteo)
+	if err == nil {
+		t.Fatalf("Expected an error but got nil")
+	}
+
+	expectedErrorMsg := `unknown weather provider "unknown"`
+	if !strings.Contains(err.Error(), expectedErrorMsg) {
+		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
+	}
+}
+
+func TestNewWeatherProviderChain_Empty(t *testing.T) {
+	_, err := service.NewWeatherProviderChain(nil)
+	if err == nil {
+		t.Fatalf("Expected an error but got nil")
+	}
+}
+
+func TestWeatherByCoordinatesProvider_WithoutCoordinates(t *testing.T) {
+	provider := service.NewWeatherByCoordinatesProvider("open-meteo", &MockWeatherByCoordinatesRepository{Weather: &model.Weather{Temperature: 30}})
+
+	_, err := provider.GetWeather(&model.Address{City: "Cidade"}, nil, context.Background())
+	if err == nil {
+		t.Fatalf("Expected an error but got nil")
+	}
+}
@@ -2,12 +2,17 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/model"
 	"github.com/aronkst/go-telemetry-cep-temperature/internal/temperature_server/service"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
 )
 
 type MockAddressRepository struct {
@@ -15,7 +20,7 @@ type MockAddressRepository struct {
 	Err     error
 }
 
-func (m *MockAddressRepository) GetAddress(string, context.Context, context.Context) (*model.Address, error) {
+func (m *MockAddressRepository) GetAddress(string, context.Context) (*model.Address, error) {
 	return m.Address, m.Err
 }
 
@@ -24,7 +29,7 @@ type MockCoordinatesRepository struct {
 	Err         error
 }
 
-func (m *MockCoordinatesRepository) GetCoordinates(*model.Address, context.Context, context.Context) (*model.Coordinates, error) {
+func (m *MockCoordinatesRepository) GetCoordinates(*model.Address, context.Context) (*model.Coordinates, error) {
 	return m.Coordinates, m.Err
 }
 
@@ -33,7 +38,7 @@ type MockWeatherByAddressRepository struct {
 	Err     error
 }
 
-func (m *MockWeatherByAddressRepository) GetWeather(*model.Address, context.Context, context.Context) (*model.Weather, error) {
+func (m *MockWeatherByAddressRepository) GetWeather(*model.Address, context.Context) (*model.Weather, error) {
 	return m.Weather, m.Err
 }
 
@@ -42,19 +47,26 @@ type MockWeatherByCoordinatesRepository struct {
 	Err     error
 }
 
-func (m *MockWeatherByCoordinatesRepository) GetWeather(*model.Coordinates, context.Context, context.Context) (*model.Weather, error) {
+func (m *MockWeatherByCoordinatesRepository) GetWeather(*model.Coordinates, context.Context) (*model.Weather, error) {
 	return m.Weather, m.Err
 }
 
+func newWeatherProviders(weatherByAddressRepo *MockWeatherByAddressRepository, weatherByCoordinatesRepo *MockWeatherByCoordinatesRepository) []service.WeatherProvider {
+	return []service.WeatherProvider{
+		service.NewWeatherByCoordinatesProvider("open-meteo", weatherByCoordinatesRepo),
+		service.NewWeatherByAddressProvider("wttr.in", weatherByAddressRepo),
+	}
+}
+
 func TestWeatherService_Success(t *testing.T) {
 	mockAddressRepo := &MockAddressRepository{Address: &model.Address{PostalCode: "12345-678", Street: "Rua Exemplo", Complement: "", District: "Bairro", City: "Cidade", State: "Estado"}}
 	mockCoordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "123", Longitude: "321"}}
 	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{Weather: &model.Weather{Temperature: 30}}
 	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{Weather: &model.Weather{Temperature: 30}}
 
-	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo)
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo), nil, logging.Discard())
 
-	temperature, err := service.GetWeatherByCEP("12345678", context.Background(), context.Background())
+	temperature, err := service.GetWeatherByCEP("12345678", context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -83,6 +95,45 @@ func TestWeatherService_Success(t *testing.T) {
 	}
 }
 
+func TestWeatherService_Conditions(t *testing.T) {
+	apparentTemperature, humidity, windSpeed, windDirection, weatherCode := -4.5, 87.0, 12.6, 190.0, 3
+
+	mockAddressRepo := &MockAddressRepository{Address: &model.Address{City: "Urupema", State: "SC"}}
+	mockCoordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "-28.28", Longitude: "-49.93"}}
+	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{}
+	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{Weather: &model.Weather{
+		Temperature:         -1,
+		ApparentTemperature: &apparentTemperature,
+		Humidity:            &humidity,
+		WindSpeed:           &windSpeed,
+		WindDirection:       &windDirection,
+		WeatherCode:         &weatherCode,
+	}}
+
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo), nil, logging.Discard())
+
+	temperature, err := service.GetWeatherByCEP(" 88625-000 ", context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	body, err := json.Marshal(temperature)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `{"city":"Urupema","cep":"88625-000",` +
+		`"address":{"cep":"","logradouro":"","complemento":"","bairro":"","localidade":"Urupema","uf":"SC"},` +
+		`"temp_C":-1,"temp_F":30.2,"temp_K":272.15,` +
+		`"feels_like":{"temp_C":-4.5,"temp_F":23.9,"temp_K":268.65},"humidity":87,` +
+		`"wind":{"speed_kmh":12.6,"direction_deg":190,"direction":"S"},` +
+		`"condition":{"code":3,"description":{"en":"Overcast","pt-BR":"Nublado"}}}`
+
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+}
+
 func TestWeatherService_AddressNotFound(t *testing.T) {
 	expectedErrorMsg := "invalid zipcode"
 
@@ -91,9 +142,9 @@ func TestWeatherService_AddressNotFound(t *testing.T) {
 	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{}
 	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{}
 
-	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo)
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo), nil, logging.Discard())
 
-	_, err := service.GetWeatherByCEP("12345678", context.Background(), context.Background())
+	_, err := service.GetWeatherByCEP("12345678", context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -103,23 +154,41 @@ func TestWeatherService_AddressNotFound(t *testing.T) {
 	}
 }
 
-func TestWeatherService_ErrorWhenCoordinates(t *testing.T) {
-	expectedErrorMsg := "can not find zipcode"
+func TestWeatherService_FallbackWhenCoordinatesWeatherFails(t *testing.T) {
+	mockAddressRepo := &MockAddressRepository{Address: &model.Address{PostalCode: "12345-678", Street: "Rua Exemplo", Complement: "", District: "Bairro", City: "Cidade", State: "Estado"}}
+	mockCoordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "123", Longitude: "321"}}
+	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{Weather: &model.Weather{Temperature: 25}}
+	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{Err: fmt.Errorf("weather api returned status 500")}
+
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo), nil, logging.Discard())
+
+	temperature, err := service.GetWeatherByCEP("12345678", context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
 
+	if temperature.Celsius != 25 {
+		t.Errorf("Expected Celsius %v, got %v", 25, temperature.Celsius)
+	}
+}
+
+func TestWeatherService_ErrorWhenEveryProviderFails(t *testing.T) {
 	mockAddressRepo := &MockAddressRepository{Address: &model.Address{PostalCode: "12345-678", Street: "Rua Exemplo", Complement: "", District: "Bairro", City: "Cidade", State: "Estado"}}
 	mockCoordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "123", Longitude: "321"}}
-	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{}
-	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{Err: fmt.Errorf(expectedErrorMsg)}
+	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{Err: fmt.Errorf("wttr.in failure")}
+	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{Err: fmt.Errorf("open-meteo failure")}
 
-	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo)
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo), nil, logging.Discard())
 
-	_, err := service.GetWeatherByCEP("12345678", context.Background(), context.Background())
+	_, err := service.GetWeatherByCEP("12345678", context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
 
-	if !strings.Contains(err.Error(), expectedErrorMsg) {
-		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
+	for _, expectedErrorMsg := range []string{"open-meteo: open-meteo failure", "wttr.in: wttr.in failure"} {
+		if !strings.Contains(err.Error(), expectedErrorMsg) {
+			t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
+		}
 	}
 }
 
@@ -131,9 +200,9 @@ func TestWeatherService_ErrorWhenNotCoordinates(t *testing.T) {
 	mockWeatherByAddressRepo := &MockWeatherByAddressRepository{Err: fmt.Errorf(expectedErrorMsg)}
 	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{}
 
-	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo)
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(mockWeatherByAddressRepo, mockWeatherByCoordinatesRepo), nil, logging.Discard())
 
-	_, err := service.GetWeatherByCEP("12345678", context.Background(), context.Background())
+	_, err := service.GetWeatherByCEP("12345678", context.Background())
 	if err == nil {
 		t.Fatalf("Expected an error but got nil")
 	}
@@ -142,3 +211,47 @@ func TestWeatherService_ErrorWhenNotCoordinates(t *testing.T) {
 		t.Errorf("Error message does not match expected. \nExpected to contain: %s\nGot: %s", expectedErrorMsg, err.Error())
 	}
 }
+
+type BlockingAddressRepository struct {
+	Address *model.Address
+	Release chan struct{}
+	Calls   atomic.Int32
+}
+
+func (m *BlockingAddressRepository) GetAddress(string, context.Context) (*model.Address, error) {
+	m.Calls.Add(1)
+	<-m.Release
+	return m.Address, nil
+}
+
+func TestWeatherService_CoalescesConcurrentLookups(t *testing.T) {
+	mockAddressRepo := &BlockingAddressRepository{Address: &model.Address{City: "Cidade"}, Release: make(chan struct{})}
+	mockCoordinatesRepo := &MockCoordinatesRepository{Coordinates: &model.Coordinates{Latitude: "123", Longitude: "321"}}
+	mockWeatherByCoordinatesRepo := &MockWeatherByCoordinatesRepository{Weather: &model.Weather{Temperature: 30}}
+
+	service := service.NewWeatherService(mockAddressRepo, mockCoordinatesRepo, newWeatherProviders(&MockWeatherByAddressRepository{}, mockWeatherByCoordinatesRepo), nil, logging.Discard())
+
+	var wg sync.WaitGroup
+
+	for range 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			temperature, err := service.GetWeatherByCEP("12345678", context.Background())
+			if err != nil || temperature.City != "Cidade" {
+				t.Errorf("Unexpected %+v, %v", temperature, err)
+			}
+		}()
+	}
+
+	// Gives every lookup the time to join the one in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(mockAddressRepo.Release)
+	wg.Wait()
+
+	if calls := mockAddressRepo.Calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 address lookup, got %d", calls)
+	}
+}
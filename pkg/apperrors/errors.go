@@ -0,0 +1,116 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+var (
+	ErrInvalidCEP          = errors.New("invalid zipcode")
+	ErrCEPNotFound         = errors.New("can not find zipcode")
+	ErrLocationNotFound    = errors.New("can not find location")
+	ErrInvalidForecast     = errors.New("invalid forecast request")
+	ErrInvalidHistory      = errors.New("invalid history request")
+	ErrInvalidBatch        = errors.New("invalid batch request")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamBadPayload  = errors.New("upstream bad payload")
+	ErrTimeout             = errors.New("upstream timeout")
+	ErrCircuitOpen         = errors.New("circuit breaker is open")
+	ErrRateLimited         = errors.New("upstream rate limit reached")
+)
+
+// UpstreamError describes a failure while talking to an external API. Kind is
+// one of the sentinel errors above, so callers can use errors.Is to classify
+// it and errors.As to inspect the upstream name and the status code.
+type UpstreamError struct {
+	Upstream   string
+	StatusCode int
+	Kind       error
+	Message    string
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	errs := []error{e.Kind}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+
+	return errs
+}
+
+func NewUpstreamError(upstream string, kind error, err error, format string, args ...any) *UpstreamError {
+	return &UpstreamError{
+		Upstream: upstream,
+		Kind:     kind,
+		Message:  fmt.Sprintf(format, args...),
+		Err:      err,
+	}
+}
+
+// Transport classifies an error returned by an HTTP client call, telling
+// timeouts and the requests refused by our own rate limiter apart from any
+// other network failure.
+func Transport(upstream string, err error, format string, args ...any) *UpstreamError {
+	kind := ErrUpstreamUnavailable
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		kind = ErrRateLimited
+	case isTimeout(err):
+		kind = ErrTimeout
+	}
+
+	return NewUpstreamError(upstream, kind, err, format, args...)
+}
+
+// Status classifies an unexpected HTTP status code returned by an upstream.
+func Status(upstream string, statusCode int, format string, args ...any) *UpstreamError {
+	kind := ErrUpstreamUnavailable
+	if statusCode == http.StatusGatewayTimeout || statusCode == http.StatusRequestTimeout {
+		kind = ErrTimeout
+	}
+
+	upstreamError := NewUpstreamError(upstream, kind, nil, format, args...)
+	upstreamError.StatusCode = statusCode
+
+	return upstreamError
+}
+
+// BadPayload reports an upstream response that could not be understood.
+func BadPayload(upstream string, err error, format string, args ...any) *UpstreamError {
+	return NewUpstreamError(upstream, ErrUpstreamBadPayload, err, format, args...)
+}
+
+// IsUpstreamFailure reports whether err was caused by an upstream misbehaving,
+// as opposed to a problem with the request itself, a client that gave up or a
+// request our own rate limiter refused before it reached the upstream.
+func IsUpstreamFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+
+	return errors.Is(err, ErrUpstreamUnavailable) ||
+		errors.Is(err, ErrUpstreamBadPayload) ||
+		errors.Is(err, ErrTimeout)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netError net.Error
+
+	return errors.As(err, &netError) && netError.Timeout()
+}
@@ -0,0 +1,55 @@
+package apperrors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+func TestUpstreamError(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := apperrors.Transport("ViaCEP", cause, "error when searching for zipcode %s information", "01001000")
+
+	if err.Error() != "error when searching for zipcode 01001000 information: connection reset" {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+
+	if !errors.Is(err, apperrors.ErrUpstreamUnavailable) {
+		t.Errorf("expected %v to be ErrUpstreamUnavailable", err)
+	}
+
+	if !errors.Is(err, cause) {
+		t.Errorf("expected %v to wrap the cause", err)
+	}
+
+	var upstreamError *apperrors.UpstreamError
+	if !errors.As(err, &upstreamError) || upstreamError.Upstream != "ViaCEP" {
+		t.Errorf("expected an UpstreamError from ViaCEP, got %v", err)
+	}
+}
+
+func TestIsUpstreamFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{apperrors.Status("open-meteo", http.StatusInternalServerError, "status"), true},
+		{apperrors.BadPayload("open-meteo", nil, "payload"), true},
+		{apperrors.Transport("open-meteo", context.Canceled, "transport"), false},
+		{apperrors.Transport("open-meteo", fmt.Errorf("wait: %w", apperrors.ErrRateLimited), "transport"), false},
+		{apperrors.ErrInvalidCEP, false},
+		{apperrors.ErrCEPNotFound, false},
+		{fmt.Errorf("%w: no coordinates", apperrors.ErrLocationNotFound), false},
+		{errors.New("other"), false},
+	}
+
+	for _, test := range tests {
+		if got := apperrors.IsUpstreamFailure(test.err); got != test.want {
+			t.Errorf("IsUpstreamFailure(%v) = %v; want %v", test.err, got, test.want)
+		}
+	}
+}
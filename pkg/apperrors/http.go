@@ -0,0 +1,92 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// The errors our own services answer for a bad request, told apart by the
+// message they answer with.
+var clientErrors = []error{
+	ErrInvalidCEP,
+	ErrCEPNotFound,
+	ErrLocationNotFound,
+	ErrInvalidForecast,
+	ErrInvalidHistory,
+	ErrInvalidBatch,
+}
+
+// HTTPStatus maps an error produced by the repositories or the services to
+// the status code both servers answer with.
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidCEP):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrCEPNotFound), errors.Is(err, ErrLocationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidForecast), errors.Is(err, ErrInvalidHistory), errors.Is(err, ErrInvalidBatch):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrRateLimited):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrUpstreamUnavailable), errors.Is(err, ErrUpstreamBadPayload):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HTTPMessage returns the text written in the response body. Client errors
+// answer with the sentinel message only, so the wrapping added by the
+// services does not leak into the public contract.
+func HTTPMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidCEP):
+		return ErrInvalidCEP.Error()
+	case errors.Is(err, ErrCEPNotFound):
+		return ErrCEPNotFound.Error()
+	case errors.Is(err, ErrLocationNotFound):
+		return ErrLocationNotFound.Error()
+	default:
+		return err.Error()
+	}
+}
+
+func WriteHTTPError(w http.ResponseWriter, err error) {
+	http.Error(w, HTTPMessage(err), HTTPStatus(err))
+}
+
+// FromHTTPResponse rebuilds the error behind a response of one of our own
+// services from its status code and body, so it keeps its classification and
+// its message across the hop. Any other answer is an upstream failure.
+func FromHTTPResponse(upstream string, resp *http.Response, format string, args ...any) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	message := strings.TrimSpace(string(body))
+
+	for _, kind := range clientErrors {
+		if HTTPStatus(kind) == resp.StatusCode && strings.HasPrefix(message, kind.Error()) {
+			return &remoteError{kind: kind, message: message}
+		}
+	}
+
+	return Status(upstream, resp.StatusCode, format, args...)
+}
+
+// remoteError keeps the message answered by the service, which already starts
+// with the one of its kind.
+type remoteError struct {
+	kind    error
+	message string
+}
+
+func (e *remoteError) Error() string {
+	return e.message
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.kind
+}
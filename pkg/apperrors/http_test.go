@@ -0,0 +1,82 @@
+package apperrors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{apperrors.ErrInvalidCEP, http.StatusUnprocessableEntity},
+		{fmt.Errorf("wrapped: %w", apperrors.ErrCEPNotFound), http.StatusNotFound},
+		{fmt.Errorf("wrapped: %w", apperrors.ErrLocationNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: days", apperrors.ErrInvalidForecast), http.StatusBadRequest},
+		{fmt.Errorf("%w: range", apperrors.ErrInvalidHistory), http.StatusBadRequest},
+		{fmt.Errorf("%w: size", apperrors.ErrInvalidBatch), http.StatusBadRequest},
+		{apperrors.Status("ViaCEP", http.StatusInternalServerError, "status"), http.StatusBadGateway},
+		{apperrors.Status("ViaCEP", http.StatusGatewayTimeout, "status"), http.StatusGatewayTimeout},
+		{apperrors.Transport("ViaCEP", context.DeadlineExceeded, "transport"), http.StatusGatewayTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{apperrors.Transport("ViaCEP", errors.New("connection refused"), "transport"), http.StatusBadGateway},
+		{apperrors.BadPayload("ViaCEP", nil, "payload"), http.StatusBadGateway},
+		{apperrors.NewUpstreamError("ViaCEP", apperrors.ErrCircuitOpen, nil, "open"), http.StatusServiceUnavailable},
+		{apperrors.Transport("ViaCEP", fmt.Errorf("wait: %w", apperrors.ErrRateLimited), "transport"), http.StatusServiceUnavailable},
+		{errors.New("anything else"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if got := apperrors.HTTPStatus(test.err); got != test.want {
+			t.Errorf("HTTPStatus(%v) = %d; want %d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestHTTPMessage(t *testing.T) {
+	err := fmt.Errorf("error when getting address: %w", apperrors.ErrInvalidCEP)
+
+	if got := apperrors.HTTPMessage(err); got != "invalid zipcode" {
+		t.Errorf("HTTPMessage(%v) = %q; want %q", err, got, "invalid zipcode")
+	}
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		body       string
+		want       error
+		message    string
+	}{
+		{http.StatusUnprocessableEntity, "invalid zipcode\n", apperrors.ErrInvalidCEP, "invalid zipcode"},
+		{http.StatusNotFound, "can not find zipcode\n", apperrors.ErrCEPNotFound, "can not find zipcode"},
+		{http.StatusNotFound, "can not find location\n", apperrors.ErrLocationNotFound, "can not find location"},
+		{http.StatusBadRequest, "invalid forecast request: days must be between 1 and 16, got 20\n", apperrors.ErrInvalidForecast, "invalid forecast request: days must be between 1 and 16, got 20"},
+		{http.StatusBadRequest, "invalid batch request: at least one zipcode is required\n", apperrors.ErrInvalidBatch, "invalid batch request: at least one zipcode is required"},
+		{http.StatusBadRequest, "something else\n", apperrors.ErrUpstreamUnavailable, "status 400"},
+		{http.StatusNotFound, "404 page not found\n", apperrors.ErrUpstreamUnavailable, "status 404"},
+		{http.StatusBadGateway, "upstream unavailable\n", apperrors.ErrUpstreamUnavailable, "status 502"},
+		{http.StatusGatewayTimeout, "upstream timeout\n", apperrors.ErrTimeout, "status 504"},
+	}
+
+	for _, test := range tests {
+		resp := &http.Response{StatusCode: test.statusCode, Body: io.NopCloser(strings.NewReader(test.body))}
+
+		err := apperrors.FromHTTPResponse("Service B", resp, "status %d", test.statusCode)
+		if !errors.Is(err, test.want) || err.Error() != test.message {
+			t.Errorf("FromHTTPResponse(%d, %q) = %v; want %v", test.statusCode, test.body, err, test.want)
+		}
+
+		if apperrors.HTTPStatus(err) != test.statusCode && !apperrors.IsUpstreamFailure(err) {
+			t.Errorf("FromHTTPResponse(%d, %q) answers with %d", test.statusCode, test.body, apperrors.HTTPStatus(err))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// Map calls fn once per distinct key, with at most concurrency calls running
+// at once, and returns the results in the order of keys. Duplicated keys share
+// the result of their first occurrence. Keys not started when ctx is done get
+// its error.
+func Map[R any](keys []string, concurrency int, fn func(string, context.Context) (R, error), ctx context.Context) []Result[R] {
+	positions := make(map[string]int, len(keys))
+	unique := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if _, ok := positions[key]; !ok {
+			positions[key] = len(unique)
+			unique = append(unique, key)
+		}
+	}
+
+	results := make([]Result[R], len(unique))
+	slots := make(chan struct{}, max(concurrency, 1))
+
+	var wg sync.WaitGroup
+
+	for i, key := range unique {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = Result[R]{Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, key string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			value, err := fn(key, ctx)
+			results[i] = Result[R]{Value: value, Err: err}
+		}(i, key)
+	}
+
+	wg.Wait()
+
+	ordered := make([]Result[R], len(keys))
+	for i, key := range keys {
+		ordered[i] = results[positions[key]]
+	}
+
+	return ordered
+}
@@ -0,0 +1,88 @@
+package batch_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/batch"
+)
+
+func TestMapKeepsOrderAndDeduplicates(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+
+	results := batch.Map([]string{"b", "a", "b", "c", "a"}, 2, func(key string, ctx context.Context) (string, error) {
+		mu.Lock()
+		calls[key]++
+		mu.Unlock()
+
+		if key == "c" {
+			return "", errors.New("failed")
+		}
+
+		return key + key, nil
+	}, context.Background())
+
+	expected := []string{"bb", "aa", "bb", "", "aa"}
+
+	for i, result := range results {
+		if result.Value != expected[i] {
+			t.Errorf("Result %d: expected %q, got %q", i, expected[i], result.Value)
+		}
+	}
+
+	if results[3].Err == nil {
+		t.Error("Expected the error of c")
+	}
+
+	for key, count := range calls {
+		if count != 1 {
+			t.Errorf("Expected 1 call for %s, got %d", key, count)
+		}
+	}
+}
+
+func TestMapBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+
+	keys := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+
+	batch.Map(keys, 3, func(key string, ctx context.Context) (int, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			observed := peak.Load()
+			if current <= observed || peak.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		return 0, nil
+	}, context.Background())
+
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 concurrent calls, got %d", peak.Load())
+	}
+}
+
+func TestMapStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := batch.Map([]string{"a", "b"}, 1, func(key string, ctx context.Context) (int, error) {
+		return 1, nil
+	}, ctx)
+
+	for _, result := range results {
+		if result.Err != nil && !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected a cancellation, got %v", result.Err)
+		}
+	}
+}
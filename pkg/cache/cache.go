@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a size bounded LRU cache whose entries expire after a fixed TTL.
+// It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[K]*list.Element
+	order    *list.List
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+func New[K comparable, V any](capacity int, ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	item := element.Value.(*entry[K, V])
+	if time.Now().After(item.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return item.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	if c.capacity <= 0 || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+
+	if element, ok := c.items[key]; ok {
+		item := element.Value.(*entry[K, V])
+		item.value = value
+		item.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache[K, V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cache"
+)
+
+func TestCacheGetSet(t *testing.T) {
+	c := cache.New[string, int](2, time.Minute)
+
+	c.Set("a", 1)
+
+	if value, ok := c.Get("a"); !ok || value != 1 {
+		t.Errorf("Get(a) = %v, %v; want 1, true", value, ok)
+	}
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Get(b) returned a value for a missing key")
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.New[string, int](2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected a to be kept")
+	}
+
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d; want 2", c.Len())
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	c := cache.New[string, int](2, 10*time.Millisecond)
+
+	c.Set("a", 1)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a to be expired")
+	}
+
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d; want 0", c.Len())
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := cache.New[string, int](0, time.Minute)
+
+	c.Set("a", 1)
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a disabled cache to never store values")
+	}
+}
@@ -0,0 +1,54 @@
+package cep
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+// CEP is a Brazilian zipcode, held as its 8 digits.
+type CEP string
+
+// The formats found in forms: 01001000, 01001-000, 01.001-000 and 01001 000.
+var format = regexp.MustCompile(`^(\d{2})\.?(\d{3})[-\s]?(\d{3})$`)
+
+// Parse accepts a zipcode in any of the common formats, surrounded or not by
+// whitespace, and returns it as its 8 digits. A zipcode outside the ranges of
+// the Correios is not found, without asking any upstream.
+func Parse(value string) (CEP, error) {
+	zipcode, err := parseDigits(value)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := zipcode.State(); !ok {
+		return "", fmt.Errorf("%w: %s is outside the ranges of the Correios", apperrors.ErrCEPNotFound, zipcode.Formatted())
+	}
+
+	return zipcode, nil
+}
+
+func parseDigits(value string) (CEP, error) {
+	matches := format.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return "", fmt.Errorf("%w: %q is not a zipcode", apperrors.ErrInvalidCEP, value)
+	}
+
+	digits := matches[1] + matches[2] + matches[3]
+	if digits == "00000000" {
+		return "", fmt.Errorf("%w: %q is not a zipcode", apperrors.ErrInvalidCEP, value)
+	}
+
+	return CEP(digits), nil
+}
+
+func (c CEP) String() string {
+	return string(c)
+}
+
+// Formatted returns the zipcode as written by the Correios, as in 01001-000.
+func (c CEP) Formatted() string {
+	return string(c[:5]) + "-" + string(c[5:])
+}
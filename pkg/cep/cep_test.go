@@ -0,0 +1,92 @@
+package cep_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  cep.CEP
+	}{
+		{"01001000", "01001000"},
+		{"01001-000", "01001000"},
+		{"01.001-000", "01001000"},
+		{"01001 000", "01001000"},
+		{" 01001000 ", "01001000"},
+		{"\t88625-000\n", "88625000"},
+	}
+
+	for _, test := range tests {
+		got, err := cep.Parse(test.value)
+		if err != nil {
+			t.Errorf("Parse(%q): expected no error, got %v", test.value, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("Parse(%q): expected %s, got %s", test.value, test.want, got)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{"", "0100100", "010010000", "0100100a", "+1001000", "01-001000", "01001--000", "01001-0000", "00000000", "０１００１０００"} {
+		if _, err := cep.Parse(value); !errors.Is(err, apperrors.ErrInvalidCEP) {
+			t.Errorf("Parse(%q): expected ErrInvalidCEP, got %v", value, err)
+		}
+	}
+}
+
+func TestFormatted(t *testing.T) {
+	parsed, _ := cep.Parse("01.001-000")
+
+	if parsed.Formatted() != "01001-000" || parsed.String() != "01001000" {
+		t.Errorf("Unexpected %s and %s", parsed.Formatted(), parsed.String())
+	}
+}
+
+func TestParseOutOfRange(t *testing.T) {
+	for _, value := range []string{"00100000", "00999-999", "78900000", "78999-999"} {
+		if _, err := cep.Parse(value); !errors.Is(err, apperrors.ErrCEPNotFound) {
+			t.Errorf("Parse(%q): expected ErrCEPNotFound, got %v", value, err)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"01000-000", "SP"},
+		{"19999-999", "SP"},
+		{"20040-020", "RJ"},
+		{"68900-000", "AP"},
+		{"69301-000", "RR"},
+		{"69900-000", "AC"},
+		{"70040-010", "DF"},
+		{"72800-000", "GO"},
+		{"73000-000", "DF"},
+		{"74000-000", "GO"},
+		{"76800-000", "RO"},
+		{"88625-000", "SC"},
+		{"99999-999", "RS"},
+	}
+
+	for _, test := range tests {
+		parsed, err := cep.Parse(test.value)
+		if err != nil {
+			t.Errorf("Parse(%q): expected no error, got %v", test.value, err)
+			continue
+		}
+
+		if state, ok := parsed.State(); !ok || state != test.want {
+			t.Errorf("State(%q): expected %s, got %s", test.value, test.want, state)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cep
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The ranges of zipcodes the Correios assigned to each state. Some states
+// hold more than one range, and the gaps between them belong to no state.
+//
+//go:embed ranges.csv
+var rangesCSV string
+
+type stateRange struct {
+	state       string
+	first, last CEP
+}
+
+var ranges = mustParseRanges(rangesCSV)
+
+func mustParseRanges(data string) []stateRange {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Errorf("error when reading the zipcode ranges: %w", err))
+	}
+
+	parsed := make([]stateRange, 0, len(records))
+
+	// Skips the header.
+	for _, record := range records[1:] {
+		first, firstErr := parseDigits(record[1])
+		last, lastErr := parseDigits(record[2])
+		if firstErr != nil || lastErr != nil || first > last {
+			panic(fmt.Errorf("invalid zipcode range %v", record))
+		}
+
+		parsed = append(parsed, stateRange{state: record[0], first: first, last: last})
+	}
+
+	slices.SortFunc(parsed, func(a, b stateRange) int {
+		return strings.Compare(string(a.first), string(b.first))
+	})
+
+	for i := 1; i < len(parsed); i++ {
+		if parsed[i].first <= parsed[i-1].last {
+			panic(fmt.Errorf("zipcode range %s-%s overlaps %s-%s", parsed[i].first, parsed[i].last, parsed[i-1].first, parsed[i-1].last))
+		}
+	}
+
+	return parsed
+}
+
+// State returns the state, as in SP, whose range holds the zipcode, or false
+// when the zipcode falls in a gap between the ranges.
+func (c CEP) State() (string, bool) {
+	i := sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].last >= c
+	})
+
+	if i == len(ranges) || ranges[i].first > c {
+		return "", false
+	}
+
+	return ranges[i].state, true
+}
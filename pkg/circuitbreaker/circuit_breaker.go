@@ -0,0 +1,168 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting trial
+	// requests through.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of trial requests allowed while half
+	// open, all of which must succeed to close the breaker again.
+	HalfOpenMaxRequests int
+	// IsFailure decides which errors count as failures. Errors caused by the
+	// request itself, like an invalid CEP, must not open the breaker.
+	IsFailure func(error) bool
+}
+
+type CircuitBreaker struct {
+	name     string
+	settings Settings
+
+	mu                sync.Mutex
+	state             State
+	failures          int
+	openedAt          time.Time
+	halfOpenRequests  int
+	halfOpenSuccesses int
+}
+
+func New(name string, settings Settings) *CircuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 1
+	}
+
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+
+	if settings.IsFailure == nil {
+		settings.IsFailure = apperrors.IsUpstreamFailure
+	}
+
+	circuitBreaker := &CircuitBreaker{
+		name:     name,
+		settings: settings,
+	}
+
+	register(circuitBreaker)
+
+	return circuitBreaker
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.currentState()
+}
+
+// Allow must be called before each request. It fails fast with an
+// ErrCircuitOpen error when the request must not reach the upstream, and
+// otherwise the caller must report the outcome through Done.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case StateOpen:
+		return b.openError()
+	case StateHalfOpen:
+		if b.halfOpenRequests >= b.settings.HalfOpenMaxRequests {
+			return b.openError()
+		}
+
+		b.halfOpenRequests++
+	}
+
+	return nil
+}
+
+func (b *CircuitBreaker) Done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failure := err != nil && b.settings.IsFailure(err)
+
+	switch b.currentState() {
+	case StateClosed:
+		if !failure {
+			b.failures = 0
+			return
+		}
+
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.open()
+		}
+	case StateHalfOpen:
+		if failure {
+			b.open()
+			return
+		}
+
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.settings.HalfOpenMaxRequests {
+			b.close()
+		}
+	}
+}
+
+// currentState moves an open breaker to half open once the open timeout has
+// elapsed. It must be called with the lock held.
+func (b *CircuitBreaker) currentState() State {
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.settings.OpenTimeout {
+		b.state = StateHalfOpen
+		b.halfOpenRequests = 0
+		b.halfOpenSuccesses = 0
+	}
+
+	return b.state
+}
+
+func (b *CircuitBreaker) open() {
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+}
+
+func (b *CircuitBreaker) close() {
+	b.state = StateClosed
+	b.failures = 0
+}
+
+func (b *CircuitBreaker) openError() error {
+	return apperrors.NewUpstreamError(b.name, apperrors.ErrCircuitOpen, nil, "circuit breaker for %s is open", b.name)
+}
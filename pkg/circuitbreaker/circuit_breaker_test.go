@@ -0,0 +1,102 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/circuitbreaker"
+)
+
+var errUpstream = apperrors.Status("upstream", 500, "upstream returned status 500")
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	breaker := circuitbreaker.New("test", circuitbreaker.Settings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		breaker.Done(errUpstream)
+	}
+
+	if breaker.State() != circuitbreaker.StateOpen {
+		t.Fatalf("Expected state %v, got %v", circuitbreaker.StateOpen, breaker.State())
+	}
+
+	err := breaker.Allow()
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	breaker := circuitbreaker.New("test", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	breaker.Allow()
+	breaker.Done(apperrors.ErrCEPNotFound)
+
+	if breaker.State() != circuitbreaker.StateClosed {
+		t.Errorf("Expected state %v, got %v", circuitbreaker.StateClosed, breaker.State())
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	breaker := circuitbreaker.New("test", circuitbreaker.Settings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	breaker.Allow()
+	breaker.Done(errUpstream)
+	breaker.Allow()
+	breaker.Done(nil)
+	breaker.Allow()
+	breaker.Done(errUpstream)
+
+	if breaker.State() != circuitbreaker.StateClosed {
+		t.Errorf("Expected state %v, got %v", circuitbreaker.StateClosed, breaker.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	breaker := circuitbreaker.New("test", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond, HalfOpenMaxRequests: 1})
+
+	breaker.Allow()
+	breaker.Done(errUpstream)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if breaker.State() != circuitbreaker.StateHalfOpen {
+		t.Fatalf("Expected state %v, got %v", circuitbreaker.StateHalfOpen, breaker.State())
+	}
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected the trial request to be allowed, got %v", err)
+	}
+
+	if err := breaker.Allow(); !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Errorf("Expected a second trial request to be rejected, got %v", err)
+	}
+
+	breaker.Done(nil)
+
+	if breaker.State() != circuitbreaker.StateClosed {
+		t.Errorf("Expected state %v, got %v", circuitbreaker.StateClosed, breaker.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	breaker := circuitbreaker.New("test", circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+
+	breaker.Allow()
+	breaker.Done(errUpstream)
+
+	time.Sleep(20 * time.Millisecond)
+
+	breaker.Allow()
+	breaker.Done(errUpstream)
+
+	if breaker.State() != circuitbreaker.StateOpen {
+		t.Errorf("Expected state %v, got %v", circuitbreaker.StateOpen, breaker.State())
+	}
+}
@@ -0,0 +1,48 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	registryMu      sync.Mutex
+	registry        []*CircuitBreaker
+	registerMetrics sync.Once
+)
+
+// register exports the state of every breaker through a single observable
+// gauge, where 0 is closed, 1 is half open and 2 is open.
+func register(circuitBreaker *CircuitBreaker) {
+	registryMu.Lock()
+	registry = append(registry, circuitBreaker)
+	registryMu.Unlock()
+
+	registerMetrics.Do(func() {
+		meter := otel.Meter("CircuitBreaker")
+
+		_, err := meter.Int64ObservableGauge(
+			"circuit_breaker.state",
+			metric.WithDescription("State of the circuit breaker: 0 closed, 1 half-open, 2 open."),
+			metric.WithInt64Callback(observe),
+		)
+		if err != nil {
+			otel.Handle(err)
+		}
+	})
+}
+
+func observe(_ context.Context, observer metric.Int64Observer) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, circuitBreaker := range registry {
+		observer.Observe(int64(circuitBreaker.State()), metric.WithAttributes(attribute.String("circuit_breaker.name", circuitBreaker.Name())))
+	}
+
+	return nil
+}
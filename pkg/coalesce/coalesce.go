@@ -0,0 +1,71 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Group deduplicates concurrent calls by key: while a call for a key is in
+// flight, later callers wait for it and share its result instead of making
+// their own. It is safe for concurrent use.
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+type call[V any] struct {
+	done   chan struct{}
+	leader trace.SpanContext
+	value  V
+	err    error
+}
+
+func New[K comparable, V any]() *Group[K, V] {
+	return &Group[K, V]{
+		calls: make(map[K]*call[V]),
+	}
+}
+
+// Do calls fn unless a call for key is already in flight, in which case it
+// waits for that one. fn runs without the cancellation of the caller that
+// started it, so a caller going away does not fail the others, and every
+// caller stops waiting when its own ctx is done. leader is the span context
+// of the caller that started fn, or invalid when it was this caller.
+func (g *Group[K, V]) Do(key K, fn func(context.Context) (V, error), ctx context.Context) (value V, leader trace.SpanContext, err error) {
+	g.mu.Lock()
+
+	c, shared := g.calls[key]
+	if !shared {
+		c = &call[V]{done: make(chan struct{}), leader: trace.SpanContextFromContext(ctx)}
+		g.calls[key] = c
+
+		go g.run(key, c, fn, context.WithoutCancel(ctx))
+	}
+
+	g.mu.Unlock()
+
+	if shared {
+		leader = c.leader
+	}
+
+	select {
+	case <-c.done:
+		return c.value, leader, c.err
+	case <-ctx.Done():
+		return value, leader, ctx.Err()
+	}
+}
+
+func (g *Group[K, V]) run(key K, c *call[V], fn func(context.Context) (V, error), ctx context.Context) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	c.value, c.err = fn(ctx)
+}
@@ -0,0 +1,157 @@
+package coalesce_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/coalesce"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDoSharesInFlightCalls(t *testing.T) {
+	group := coalesce.New[string, int]()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	leaderSpan := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	leaderCtx := trace.ContextWithSpanContext(context.Background(), leaderSpan)
+
+	fn := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		value, leader, err := group.Do("01001000", fn, leaderCtx)
+		if value != 42 || leader.IsValid() || err != nil {
+			t.Errorf("Leader: unexpected %d, %v, %v", value, leader, err)
+		}
+	}()
+
+	// Gives the leader the time to start the call.
+	time.Sleep(20 * time.Millisecond)
+
+	for range 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			value, leader, err := group.Do("01001000", fn, context.Background())
+			if value != 42 || leader.SpanID() != leaderSpan.SpanID() || err != nil {
+				t.Errorf("Waiter: unexpected %d, %v, %v", value, leader, err)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestDoCallsAgainOnceDone(t *testing.T) {
+	group := coalesce.New[string, int]()
+
+	var calls atomic.Int32
+
+	fn := func(ctx context.Context) (int, error) {
+		return int(calls.Add(1)), errors.New("failed")
+	}
+
+	for i := 1; i <= 2; i++ {
+		value, _, err := group.Do("key", fn, context.Background())
+		if value != i || err == nil {
+			t.Errorf("Call %d: unexpected %d, %v", i, value, err)
+		}
+	}
+}
+
+func TestDoWaiterGivesUp(t *testing.T) {
+	group := coalesce.New[string, int]()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+
+	go group.Do("key", func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}, context.Background())
+
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := group.Do("key", func(ctx context.Context) (int, error) { return 2, nil }, ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation, got %v", err)
+	}
+}
+
+func TestDoLeaderCancellationDoesNotFailTheWaiters(t *testing.T) {
+	group := coalesce.New[string, int]()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var once sync.Once
+
+	fn := func(ctx context.Context) (int, error) {
+		once.Do(func() { close(started) })
+		<-release
+		return 1, ctx.Err()
+	}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+
+	go func() {
+		_, _, err := group.Do("key", fn, leaderCtx)
+		leaderErr <- err
+	}()
+
+	<-started
+
+	waiterDone := make(chan struct{})
+
+	go func() {
+		defer close(waiterDone)
+
+		value, _, err := group.Do("key", fn, context.Background())
+		if value != 1 || err != nil {
+			t.Errorf("Waiter: expected 1, got %d, %v", value, err)
+		}
+	}()
+
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Leader: expected a cancellation, got %v", err)
+	}
+
+	close(release)
+	<-waiterDone
+}
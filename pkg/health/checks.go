@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// HTTPCheck considers the upstream reachable when it answers a HEAD request
+// to url with anything but a 5xx.
+func HTTPCheck(client *http.Client, url string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return fmt.Errorf("error when creating request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s answered status %d", url, resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// ServiceCheck considers one of our own services up when it answers a GET
+// request to url, usually its liveness endpoint, with a 2xx.
+func ServiceCheck(client *http.Client, url string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("error when creating request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("%s answered status %d", url, resp.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// TCPCheck considers the address reachable when a TCP connection to it can be
+// opened, as for the gRPC receiver of the collector.
+func TCPCheck(address string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
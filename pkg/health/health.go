@@ -0,0 +1,227 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dependency is something the service needs to answer requests. A failing
+// critical dependency makes the service not ready, while the others are only
+// reported. A dependency with Members has no check of its own: it is up when
+// at least one of them is, as for providers of the same data asked in turn.
+type Dependency struct {
+	Name     string
+	Critical bool
+	Check    func(context.Context) error
+	Members  []string
+}
+
+type Status struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Latency   string    `json:"latency"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status       string            `json:"status"`
+	Dependencies map[string]Status `json:"dependencies"`
+}
+
+// NewDependencies builds the dependencies from their checks, sorted by name,
+// flagging the ones listed in critical.
+func NewDependencies(checks map[string]func(context.Context) error, critical []string) []Dependency {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dependencies := make([]Dependency, 0, len(names))
+	for _, name := range names {
+		dependencies = append(dependencies, Dependency{
+			Name:     name,
+			Critical: slices.Contains(critical, name),
+			Check:    checks[name],
+		})
+	}
+
+	return dependencies
+}
+
+// NewGroup builds a dependency up when at least one of members is, critical
+// when listed in critical.
+func NewGroup(name string, members []string, critical []string) Dependency {
+	return Dependency{
+		Name:     name,
+		Critical: slices.Contains(critical, name),
+		Members:  members,
+	}
+}
+
+// Health answers the liveness and readiness probes. The result of each check
+// is kept for cacheTTL, so frequent probes do not hammer the upstreams.
+type Health struct {
+	dependencies []Dependency
+	cacheTTL     time.Duration
+	timeout      time.Duration
+
+	mu      sync.Mutex
+	results map[string]Status
+}
+
+func New(cacheTTL time.Duration, timeout time.Duration, dependencies ...Dependency) *Health {
+	return &Health{
+		dependencies: dependencies,
+		cacheTTL:     cacheTTL,
+		timeout:      timeout,
+		results:      make(map[string]Status, len(dependencies)),
+	}
+}
+
+// Liveness only tells the process is able to answer.
+func (h *Health) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Readiness reports the status of every dependency, answering 503 when a
+// critical one is down.
+func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Check(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if report.Status != "ready" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// Check runs the checks whose cached result expired, concurrently, and
+// returns the report of every dependency.
+func (h *Health) Check(ctx context.Context) Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	fresh := make([]*Status, len(h.dependencies))
+
+	for i, dependency := range h.dependencies {
+		if dependency.Check == nil {
+			continue
+		}
+
+		if result, ok := h.results[dependency.Name]; ok && now.Sub(result.CheckedAt) < h.cacheTTL {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, dependency Dependency) {
+			defer wg.Done()
+
+			result := h.check(dependency, ctx)
+			fresh[i] = &result
+		}(i, dependency)
+	}
+
+	wg.Wait()
+
+	for i, result := range fresh {
+		if result != nil {
+			h.results[h.dependencies[i].Name] = *result
+		}
+	}
+
+	report := Report{
+		Status:       "ready",
+		Dependencies: make(map[string]Status, len(h.dependencies)),
+	}
+
+	for _, dependency := range h.dependencies {
+		result := h.results[dependency.Name]
+		if dependency.Check == nil {
+			result = h.group(dependency)
+		}
+
+		report.Dependencies[dependency.Name] = result
+
+		if dependency.Critical && result.Status != "up" {
+			report.Status = "not_ready"
+		}
+	}
+
+	return report
+}
+
+// group reports the oldest and slowest of the results of the members of
+// dependency. It must be called with the lock held.
+func (h *Health) group(dependency Dependency) Status {
+	result := Status{
+		Status:   "down",
+		Critical: dependency.Critical,
+		Error:    fmt.Sprintf("none of %s is up", strings.Join(dependency.Members, ", ")),
+	}
+
+	var latency time.Duration
+
+	for _, member := range dependency.Members {
+		status, ok := h.results[member]
+		if !ok {
+			continue
+		}
+
+		if status.Status == "up" {
+			result.Status = "up"
+			result.Error = ""
+		}
+
+		if memberLatency, err := time.ParseDuration(status.Latency); err == nil {
+			latency = max(latency, memberLatency)
+		}
+
+		if result.CheckedAt.IsZero() || status.CheckedAt.Before(result.CheckedAt) {
+			result.CheckedAt = status.CheckedAt
+		}
+	}
+
+	result.Latency = latency.String()
+
+	return result
+}
+
+func (h *Health) check(dependency Dependency, ctx context.Context) Status {
+	// The probe must not be cancelled by the request that triggered it, as
+	// its result is shared with the next probes.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dependency.Check(ctx)
+
+	result := Status{
+		Status:    "up",
+		Critical:  dependency.Critical,
+		Latency:   time.Since(start).Round(time.Millisecond).String(),
+		CheckedAt: start,
+	}
+
+	if err != nil {
+		result.Status = "down"
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,248 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/health"
+)
+
+func TestLiveness(t *testing.T) {
+	h := health.New(time.Minute, time.Second)
+
+	rec := httptest.NewRecorder()
+	h.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name           string
+		checks         map[string]func(context.Context) error
+		critical       []string
+		expectedStatus int
+		expectedReport string
+	}{
+		{
+			name:           "all up",
+			checks:         map[string]func(context.Context) error{"viacep": up, "collector": up},
+			critical:       []string{"viacep"},
+			expectedStatus: http.StatusOK,
+			expectedReport: "ready",
+		},
+		{
+			name:           "non critical down",
+			checks:         map[string]func(context.Context) error{"viacep": up, "collector": down},
+			critical:       []string{"viacep"},
+			expectedStatus: http.StatusOK,
+			expectedReport: "ready",
+		},
+		{
+			name:           "critical down",
+			checks:         map[string]func(context.Context) error{"viacep": down, "collector": up},
+			critical:       []string{"viacep"},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: "not_ready",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := health.New(time.Minute, time.Second, health.NewDependencies(test.checks, test.critical)...)
+
+			rec := httptest.NewRecorder()
+			h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != test.expectedStatus {
+				t.Errorf("Expected status %d, got %d", test.expectedStatus, rec.Code)
+			}
+
+			var report health.Report
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if report.Status != test.expectedReport {
+				t.Errorf("Expected report %q, got %q", test.expectedReport, report.Status)
+			}
+
+			if len(report.Dependencies) != len(test.checks) {
+				t.Errorf("Expected %d dependencies, got %d", len(test.checks), len(report.Dependencies))
+			}
+
+			if collector := report.Dependencies["collector"]; collector.Critical {
+				t.Error("Expected collector not to be critical")
+			}
+		})
+	}
+}
+
+func TestCheckGroup(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name           string
+		checks         map[string]func(context.Context) error
+		expectedReport string
+	}{
+		{"one member up", map[string]func(context.Context) error{"viacep": down, "brasilapi": up}, "ready"},
+		{"every member down", map[string]func(context.Context) error{"viacep": down, "brasilapi": down}, "not_ready"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			critical := []string{"address-providers"}
+
+			dependencies := append(health.NewDependencies(test.checks, critical), health.NewGroup("address-providers", []string{"viacep", "brasilapi"}, critical))
+
+			report := health.New(time.Minute, time.Second, dependencies...).Check(context.Background())
+
+			if report.Status != test.expectedReport {
+				t.Errorf("Expected report %q, got %+v", test.expectedReport, report)
+			}
+
+			if group := report.Dependencies["address-providers"]; !group.Critical || group.CheckedAt.IsZero() {
+				t.Errorf("Expected a critical group checked with its members, got %+v", group)
+			}
+
+			if viacep := report.Dependencies["viacep"]; viacep.Critical || viacep.Status != "down" {
+				t.Errorf("Expected the members to be reported on their own, got %+v", viacep)
+			}
+		})
+	}
+}
+
+func TestCheckCachesResults(t *testing.T) {
+	var calls atomic.Int32
+
+	check := func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+
+	h := health.New(time.Minute, time.Second, health.Dependency{Name: "viacep", Critical: true, Check: check})
+
+	h.Check(context.Background())
+	h.Check(context.Background())
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 probe, got %d", calls.Load())
+	}
+
+	uncached := health.New(0, time.Second, health.Dependency{Name: "viacep", Critical: true, Check: check})
+
+	uncached.Check(context.Background())
+	uncached.Check(context.Background())
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 probes, got %d", calls.Load())
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	h := health.New(time.Minute, 10*time.Millisecond, health.Dependency{Name: "slow", Critical: true, Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	report := h.Check(context.Background())
+
+	if report.Status != "not_ready" || report.Dependencies["slow"].Error == "" {
+		t.Errorf("Expected the slow dependency to be down, got %+v", report)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectedErr bool
+	}{
+		{"ok", http.StatusOK, false},
+		{"not found", http.StatusNotFound, false},
+		{"server error", http.StatusBadGateway, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+			}))
+			defer upstream.Close()
+
+			err := health.HTTPCheck(upstream.Client(), upstream.URL)(context.Background())
+
+			if (err != nil) != test.expectedErr {
+				t.Errorf("Expected error %v, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestServiceCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		status      int
+		expectedErr bool
+	}{
+		{"ok", http.MethodGet, http.StatusOK, false},
+		{"not found", http.MethodGet, http.StatusNotFound, true},
+		{"method not allowed", http.MethodPost, http.StatusOK, true},
+		{"server error", http.MethodGet, http.StatusServiceUnavailable, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Answers like a router with the route registered for method only.
+			service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != test.method {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+
+				w.WriteHeader(test.status)
+			}))
+			defer service.Close()
+
+			err := health.ServiceCheck(service.Client(), service.URL+"/healthz")(context.Background())
+
+			if (err != nil) != test.expectedErr {
+				t.Errorf("Expected error %v, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	address := listener.Addr().String()
+
+	if err := health.TCPCheck(address)(context.Background()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	listener.Close()
+
+	if err := health.TCPCheck(address)(context.Background()); err == nil {
+		t.Error("Expected an error, got nil")
+	}
+}
@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"net/http"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+)
+
+type Settings struct {
+	Retry RetrySettings
+	// UserAgent and Contact identify the service to the upstreams, as asked
+	// by their usage policies. Contact is sent in the From header.
+	UserAgent string
+	Contact   string
+	// Limiters maps an upstream host to its limiter, as built by NewLimiters.
+	// Clients given the same limiters share the rate of each host. Hosts left
+	// out are not limited.
+	Limiters map[string]*Limiter
+}
+
+// NewLimiters builds one limiter per host from the requests per second each
+// host may receive from the whole process. Hosts with a rate of zero are not
+// limited.
+func NewLimiters(rates map[string]float64) map[string]*Limiter {
+	limiters := make(map[string]*Limiter, len(rates))
+	for host, rate := range rates {
+		if rate > 0 {
+			limiters[host] = NewLimiter(rate)
+		}
+	}
+
+	return limiters
+}
+
+// New builds the HTTP client shared by every repository. It has no global
+// timeout: each repository bounds its own calls through the request context,
+// so every upstream gets its own timeout. The retries wrap the rate limiter
+// and the instrumented transport, so every attempt waits for its turn and is
+// reported as its own client span.
+func New(settings Settings) *http.Client {
+	return &http.Client{
+		Transport: &HeaderTransport{
+			Base: &RetryTransport{
+				Base: &RateLimitTransport{
+					Base:     telemetry.NewTransport(http.DefaultTransport.(*http.Transport).Clone()),
+					Limiters: settings.Limiters,
+				},
+				Settings: settings.Retry,
+			},
+			UserAgent: settings.UserAgent,
+			Contact:   settings.Contact,
+		},
+	}
+}
+
+// NewProbe builds the client of the health checks. It identifies itself like
+// the client of New, but goes without retries nor tracing, so the probes
+// neither hide a failing upstream nor flood the traces. It does not wait for
+// the limiters either: a probe refused because of the live traffic would
+// report a healthy upstream as down, and the health checks cache their
+// results, so each upstream is probed at most once per cache TTL.
+func NewProbe(settings Settings) *http.Client {
+	return &http.Client{
+		Transport: &HeaderTransport{
+			Base:      http.DefaultTransport.(*http.Transport).Clone(),
+			UserAgent: settings.UserAgent,
+			Contact:   settings.Contact,
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package httpclient
+
+import "net/http"
+
+// HeaderTransport sets the User-Agent and From headers of the requests that
+// do not set them already.
+type HeaderTransport struct {
+	Base      http.RoundTripper
+	UserAgent string
+	Contact   string
+}
+
+func (t *HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	setUserAgent := t.UserAgent != "" && req.Header.Get("User-Agent") == ""
+	setContact := t.Contact != "" && req.Header.Get("From") == ""
+
+	if !setUserAgent && !setContact {
+		return t.Base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+
+	if setUserAgent {
+		req.Header.Set("User-Agent", t.UserAgent)
+	}
+
+	if setContact {
+		req.Header.Set("From", t.Contact)
+	}
+
+	return t.Base.RoundTrip(req)
+}
@@ -0,0 +1,117 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Limiter is a token bucket refilled at a fixed rate. Callers take a token
+// each and queue in arrival order when the bucket is empty. It is safe for
+// concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter allows rate requests per second, rate being positive, with
+// bursts of as many requests as the rate allows in a second, and at least one.
+func NewLimiter(rate float64) *Limiter {
+	burst := math.Max(1, math.Floor(rate))
+
+	return &Limiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// Wait takes a token, blocking until it is available, and returns how long it
+// waited. It fails at once with ErrRateLimited when ctx has a deadline that
+// comes before the token, and gives the token back when ctx is done while
+// waiting.
+func (l *Limiter) Wait(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve(time.Now())
+	if delay == 0 {
+		return 0, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		l.release()
+		return 0, fmt.Errorf("rate limit wait of %s exceeds the deadline: %w", delay, apperrors.ErrRateLimited)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.release()
+		return 0, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait for it.
+// The tokens go negative while callers are queued.
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+}
+
+// RateLimitTransport makes every request wait for the limiter of its host.
+// The wait is recorded as an event of the span of the request context.
+type RateLimitTransport struct {
+	Base     http.RoundTripper
+	Limiters map[string]*Limiter
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter, ok := t.Limiters[req.URL.Host]
+	if !ok {
+		return t.Base.RoundTrip(req)
+	}
+
+	waited, err := limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	if waited > 0 {
+		trace.SpanFromContext(req.Context()).AddEvent("rate_limit.wait", trace.WithAttributes(
+			attribute.String("server.address", req.URL.Host),
+			attribute.Float64("rate_limit.wait_seconds", waited.Seconds()),
+		))
+	}
+
+	return t.Base.RoundTrip(req)
+}
@@ -0,0 +1,174 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/httpclient"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLimiter_QueuesCallersOnceTheBurstIsSpent(t *testing.T) {
+	limiter := httpclient.NewLimiter(20)
+
+	for i := range 20 {
+		if waited, err := limiter.Wait(context.Background()); waited != 0 || err != nil {
+			t.Fatalf("Call %d: expected no wait, got %s, %v", i, waited, err)
+		}
+	}
+
+	start := time.Now()
+
+	if _, err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected to wait about 50ms for the next token, waited %s", elapsed)
+	}
+}
+
+func TestLimiter_FailsWhenTheDeadlineComesFirst(t *testing.T) {
+	limiter := httpclient.NewLimiter(1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err := limiter.Wait(ctx)
+	if !errors.Is(err, apperrors.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("Expected to fail at once, waited %s", elapsed)
+	}
+
+	// The token of the failed call is given back, so the next caller waits
+	// for a single token.
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if waited, err := limiter.Wait(ctx); err != nil || waited > time.Second {
+		t.Errorf("Expected to wait at most 1s, got %s, %v", waited, err)
+	}
+}
+
+func TestClient_LimitsAndIdentifiesRequests(t *testing.T) {
+	var userAgent, from string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		from = r.Header.Get("From")
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	client := httpclient.New(httpclient.Settings{
+		UserAgent: "cep-temperature/1.0",
+		Contact:   "ops@example.com",
+		Limiters:  httpclient.NewLimiters(map[string]float64{serverURL.Host: 1}),
+	})
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if userAgent != "cep-temperature/1.0" || from != "ops@example.com" {
+		t.Errorf("Unexpected User-Agent %q and From %q", userAgent, from)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	if _, err := client.Do(req); !errors.Is(err, apperrors.ErrRateLimited) {
+		t.Errorf("Expected the second request to be refused by the limiter, got %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRateLimitTransport_RecordsTheWaitAsASpanEvent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	ctx, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "request")
+
+	transport := &httpclient.RateLimitTransport{
+		Base: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		Limiters: map[string]*httpclient.Limiter{"nominatim.openstreetmap.org": httpclient.NewLimiter(20)},
+	}
+
+	for range 21 {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://nominatim.openstreetmap.org/search", nil)
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 1 || events[0].Name != "rate_limit.wait" {
+		t.Errorf("Expected a single rate_limit.wait event, got %+v", events)
+	}
+}
+
+func TestNewProbe_IgnoresTheLimitersAndIdentifiesRequests(t *testing.T) {
+	var userAgent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	settings := httpclient.Settings{
+		UserAgent: "cep-temperature/1.0",
+		Limiters:  httpclient.NewLimiters(map[string]float64{serverURL.Host: 1, "unlimited.local": 0}),
+	}
+
+	resp, err := httpclient.New(settings).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	resp, err = httpclient.NewProbe(settings).Do(req)
+	if err != nil {
+		t.Fatalf("Expected the probe not to wait for the token spent by the other client, got %v", err)
+	}
+	resp.Body.Close()
+
+	if _, ok := settings.Limiters["unlimited.local"]; ok {
+		t.Error("Expected no limiter for a rate of zero")
+	}
+
+	if userAgent != "cep-temperature/1.0" {
+		t.Errorf("Unexpected User-Agent %q", userAgent)
+	}
+}
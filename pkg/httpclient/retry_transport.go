@@ -0,0 +1,128 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+)
+
+type RetrySettings struct {
+	// MaxRetries is the number of attempts made after the first one.
+	MaxRetries int
+	// BaseDelay is the upper bound of the first backoff, doubled on every
+	// attempt until it reaches MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay bounds every wait, including the ones asked by Retry-After.
+	MaxDelay time.Duration
+}
+
+// RetryTransport retries idempotent requests that failed with a network error,
+// a 5xx or a 429, waiting a jittered exponential backoff between attempts or
+// the delay asked by the upstream through the Retry-After header, capped at
+// MaxDelay. The waits honor the request context, so a cancelled request stops
+// retrying at once.
+type RetryTransport struct {
+	Base     http.RoundTripper
+	Settings RetrySettings
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) || t.Settings.MaxRetries <= 0 {
+		return t.Base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+
+		if attempt >= t.Settings.MaxRetries || !shouldRetry(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(retryAfter, t.Settings.MaxDelay)
+			}
+		}
+
+		// There is no point in waiting for an attempt the deadline will not
+		// allow, so the last answer is returned as is.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	ceiling := t.Settings.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > t.Settings.MaxDelay {
+		ceiling = t.Settings.MaxDelay
+	}
+
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func isIdempotent(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+
+	return req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == ""
+}
+
+// A request refused by the rate limiter would only be refused again.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, apperrors.ErrRateLimited)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// parseRetryAfter reads both forms allowed for the Retry-After header: a
+// number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+
+		return delay, true
+	}
+
+	return 0, false
+}
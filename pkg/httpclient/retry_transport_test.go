@@ -0,0 +1,198 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/httpclient"
+)
+
+var settings = httpclient.Settings{Retry: httpclient.RetrySettings{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}}
+
+// slowSettings lets the Retry-After delays of a few seconds through.
+var slowSettings = httpclient.Settings{Retry: httpclient.RetrySettings{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Minute}}
+
+func TestRetryTransport_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	resp, err := httpclient.New(settings).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestRetryTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	resp, err := httpclient.New(settings).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, resp.StatusCode)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestRetryTransport_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	resp, err := httpclient.New(settings).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestRetryTransport_DoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	resp, err := httpclient.New(settings).Post(server.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestRetryTransport_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+
+	resp, err := httpclient.New(slowSettings).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected to wait for the Retry-After delay, waited %s", elapsed)
+	}
+}
+
+func TestRetryTransport_CapsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+
+	resp, err := httpclient.New(settings).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("Expected a retry answering %d, got %d after %d calls", http.StatusOK, resp.StatusCode, calls.Load())
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the Retry-After delay to be capped at MaxDelay, waited %s", elapsed)
+	}
+}
+
+func TestRetryTransport_StopsOnDeadline(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	resp, err := httpclient.New(slowSettings).Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 call, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fanoutHandler sends every record at or above level to all of its handlers,
+// so the same log line reaches the standard output and the collector.
+type fanoutHandler struct {
+	level    slog.Leveler
+	handlers []slog.Handler
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level < h.level.Level() {
+		return false
+	}
+
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return &fanoutHandler{level: h.level, handlers: handlers}
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return &fanoutHandler{level: h.level, handlers: handlers}
+}
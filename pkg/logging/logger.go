@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a logger writing JSON records to w, each one enriched with the
+// trace_id and span_id of the span found in the context of the call. The
+// records are also sent to the extra handlers, such as the one returned by
+// NewOTelHandler.
+func New(w io.Writer, level slog.Level, handlers ...slog.Handler) *slog.Logger {
+	var handler slog.Handler = &traceHandler{
+		Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}),
+	}
+
+	if len(handlers) > 0 {
+		handler = &fanoutHandler{level: level, handlers: append([]slog.Handler{handler}, handlers...)}
+	}
+
+	return slog.New(handler)
+}
+
+// NewOTelHandler returns a handler bridging the records to the global
+// OpenTelemetry logger provider, which exports them with the resource of the
+// service. The records keep the trace context of the call.
+func NewOTelHandler(name string) slog.Handler {
+	return otelslog.NewHandler(name)
+}
+
+// Discard returns a logger that drops every record.
+func Discard() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+// ParseLevel reads debug, info, warn or error, falling back to info for
+// anything else.
+func ParseLevel(value string) slog.Level {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return slog.LevelInfo
+	}
+
+	return level
+}
+
+// Error logs a failed operation when err points to a non-nil error. It is
+// meant to be deferred by functions with a named error result.
+func Error(logger *slog.Logger, msg string, err *error, ctx context.Context, args ...any) {
+	if err == nil || *err == nil {
+		return
+	}
+
+	level := slog.LevelError
+	if isExpected(*err) {
+		level = slog.LevelWarn
+	}
+
+	logger.Log(ctx, level, msg, append(args, slog.Any("error", *err))...)
+}
+
+// Bad input and cancelled requests are expected, only failures of the service
+// or of its upstreams are errors.
+var expectedErrors = []error{
+	apperrors.ErrInvalidCEP,
+	apperrors.ErrCEPNotFound,
+	apperrors.ErrLocationNotFound,
+	apperrors.ErrInvalidForecast,
+	apperrors.ErrInvalidHistory,
+	apperrors.ErrInvalidBatch,
+	context.Canceled,
+}
+
+func isExpected(err error) bool {
+	for _, expected := range expectedErrors {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
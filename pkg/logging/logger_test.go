@@ -0,0 +1,141 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func decode(t *testing.T, buffer *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	var record map[string]any
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %q", buffer.String())
+	}
+
+	return record
+}
+
+func TestNewAddsTraceContext(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := logging.New(&buffer, slog.LevelInfo).With("service", "test")
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	logger.InfoContext(ctx, "hello")
+
+	record := decode(t, &buffer)
+
+	if record["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected trace_id %s, got %v", span.SpanContext().TraceID(), record["trace_id"])
+	}
+
+	if record["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("Expected span_id %s, got %v", span.SpanContext().SpanID(), record["span_id"])
+	}
+
+	if record["service"] != "test" {
+		t.Errorf("Expected service test, got %v", record["service"])
+	}
+}
+
+func TestNewWithoutSpan(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := logging.New(&buffer, slog.LevelInfo)
+
+	logger.DebugContext(context.Background(), "hidden")
+
+	if buffer.Len() != 0 {
+		t.Fatalf("Expected debug records to be dropped, got %q", buffer.String())
+	}
+
+	logger.InfoContext(context.Background(), "hello")
+
+	if _, ok := decode(t, &buffer)["trace_id"]; ok {
+		t.Error("Expected no trace_id without a span")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		" warn ":  slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+		"":        slog.LevelInfo,
+	}
+
+	for value, expected := range tests {
+		if level := logging.ParseLevel(value); level != expected {
+			t.Errorf("ParseLevel(%q): expected %s, got %s", value, expected, level)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{apperrors.ErrCEPNotFound, "WARN"},
+		{context.Canceled, "WARN"},
+		{apperrors.Status("ViaCEP", 500, "ViaCEP api returned status 500"), "ERROR"},
+		{errors.New("unexpected"), "ERROR"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		logger := logging.New(&buffer, slog.LevelDebug)
+
+		logging.Error(logger, "request failed", &test.err, context.Background(), "cep", "01001000")
+
+		if test.expected == "" {
+			if buffer.Len() != 0 {
+				t.Errorf("Expected no record, got %q", buffer.String())
+			}
+			continue
+		}
+
+		record := decode(t, &buffer)
+
+		if record["level"] != test.expected {
+			t.Errorf("%v: expected level %s, got %v", test.err, test.expected, record["level"])
+		}
+
+		if record["cep"] != "01001000" || record["error"] != test.err.Error() {
+			t.Errorf("Expected the cep and error attributes, got %v", record)
+		}
+	}
+}
+
+func TestNewWithExtraHandlers(t *testing.T) {
+	var stdout, extra bytes.Buffer
+	logger := logging.New(&stdout, slog.LevelInfo, slog.NewJSONHandler(&extra, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	logger.DebugContext(context.Background(), "hidden")
+
+	if stdout.Len() != 0 || extra.Len() != 0 {
+		t.Fatalf("Expected debug records to be dropped by every handler, got %q and %q", stdout.String(), extra.String())
+	}
+
+	logger.With("service", "test").InfoContext(context.Background(), "hello")
+
+	for _, buffer := range []*bytes.Buffer{&stdout, &extra} {
+		record := decode(t, buffer)
+
+		if record["msg"] != "hello" || record["service"] != "test" {
+			t.Errorf("Expected the record in every handler, got %v", record)
+		}
+	}
+}
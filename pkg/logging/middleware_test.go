@@ -0,0 +1,44 @@
+package logging_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusOK, "INFO"},
+		{http.StatusNotFound, "INFO"},
+		{http.StatusBadGateway, "ERROR"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		logger := logging.New(&buffer, slog.LevelInfo)
+
+		handler := logging.Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte("body"))
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?cep=01001000", nil))
+
+		record := decode(t, &buffer)
+
+		if record["level"] != test.level {
+			t.Errorf("Expected level %s, got %v", test.level, record["level"])
+		}
+
+		if record["status"] != float64(test.status) || record["method"] != "GET" || record["path"] != "/" || record["bytes"] != float64(4) {
+			t.Errorf("Expected the request attributes, got %v", record)
+		}
+	}
+}
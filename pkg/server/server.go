@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+type Settings struct {
+	Port         int
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+func New(handler http.Handler, settings Settings) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", settings.Port),
+		Handler:      handler,
+		ReadTimeout:  settings.ReadTimeout,
+		WriteTimeout: settings.WriteTimeout,
+		IdleTimeout:  settings.IdleTimeout,
+	}
+}
+
+// Shutdown bounds the graceful shutdown. The in-flight requests are waited
+// for up to DrainTimeout, then Flush is called with a context of its own,
+// bounded by FlushTimeout, so the telemetry of the drained requests is
+// flushed even when the drain used its whole timeout.
+type Shutdown struct {
+	DrainTimeout time.Duration
+	FlushTimeout time.Duration
+	Flush        func(context.Context)
+}
+
+func (s Shutdown) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.FlushTimeout)
+	defer cancel()
+
+	s.Flush(ctx)
+}
+
+// Run listens on the address of the server and serves it until ctx is done.
+func Run(server *http.Server, shutdown Shutdown, logger *slog.Logger, ctx context.Context) error {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		shutdown.flush()
+		return fmt.Errorf("error starting server: %w", err)
+	}
+
+	return Serve(server, listener, shutdown, logger, ctx)
+}
+
+// Serve serves the listener until ctx is done. The server then stops
+// accepting connections and waits for the in-flight requests, closing the
+// remaining connections once the drain timeout elapses, and flushes.
+func Serve(server *http.Server, listener net.Listener, shutdown Shutdown, logger *slog.Logger, ctx context.Context) error {
+	defer shutdown.flush()
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	logger.Info("server started", "address", listener.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("error serving requests: %w", err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down server, draining in-flight requests", "timeout", shutdown.DrainTimeout.String())
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdown.DrainTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(drainCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("error draining in-flight requests: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving requests: %w", err)
+	}
+
+	logger.Info("server stopped")
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/logging"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/server"
+)
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	httpServer := server.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	}), server.Settings{ReadTimeout: time.Second, WriteTimeout: time.Second, IdleTimeout: time.Second})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(httpServer, listener, server.Shutdown{DrainTimeout: time.Second, FlushTimeout: time.Second, Flush: func(context.Context) {}}, logging.Discard(), ctx)
+	}()
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	// The listener is closed at once, while the in-flight request goes on.
+	time.Sleep(50 * time.Millisecond)
+	if _, err := net.Dial("tcp", listener.Addr().String()); err == nil {
+		t.Error("Expected new connections to be refused during the shutdown")
+	}
+
+	close(release)
+
+	if body := <-response; body != "done" {
+		t.Errorf("Expected the in-flight request to complete, got %q", body)
+	}
+
+	if err := <-served; err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	httpServer := server.New(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}), server.Settings{ReadTimeout: time.Second, WriteTimeout: time.Second, IdleTimeout: time.Second})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The drain uses its whole timeout, which must not shorten the flush.
+	flushed := make(chan error, 1)
+	shutdown := server.Shutdown{
+		DrainTimeout: 50 * time.Millisecond,
+		FlushTimeout: time.Second,
+		Flush: func(ctx context.Context) {
+			flushed <- ctx.Err()
+		},
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(httpServer, listener, shutdown, logging.Discard(), ctx)
+	}()
+
+	go http.Get("http://" + listener.Addr().String())
+
+	<-started
+	cancel()
+
+	err = <-served
+	if err == nil || !strings.Contains(err.Error(), "error draining in-flight requests") {
+		t.Errorf("Expected a draining error, got %v", err)
+	}
+
+	if err := <-flushed; err != nil {
+		t.Errorf("Expected the flush to get a live context, got %v", err)
+	}
+}
+
+func TestRunListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer listener.Close()
+
+	httpServer := &http.Server{Addr: listener.Addr().String()}
+
+	flushed := false
+
+	shutdown := server.Shutdown{
+		DrainTimeout: time.Second,
+		FlushTimeout: time.Second,
+		Flush:        func(context.Context) { flushed = true },
+	}
+
+	err = server.Run(httpServer, shutdown, logging.Discard(), context.Background())
+	if err == nil || !strings.Contains(err.Error(), "error starting server") {
+		t.Errorf("Expected a listen error, got %v", err)
+	}
+
+	if !flushed {
+		t.Error("Expected the telemetry to be flushed when the server fails to start")
+	}
+}
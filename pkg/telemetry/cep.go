@@ -0,0 +1,25 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/cep"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const CEPHashKey = attribute.Key("cep.hash")
+
+// CEPAttribute identifies the zipcode of a request without exporting it in
+// clear text, so traces of the same zipcode can still be grouped together.
+// The canonical form of the zipcode is hashed, whatever the format it was
+// written in, and a value that is not a zipcode is hashed as it is.
+func CEPAttribute(value string) attribute.KeyValue {
+	if zipcode, err := cep.Parse(value); err == nil {
+		value = zipcode.String()
+	}
+
+	sum := sha256.Sum256([]byte(value))
+
+	return CEPHashKey.String(hex.EncodeToString(sum[:8]))
+}
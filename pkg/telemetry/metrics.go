@@ -0,0 +1,155 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	instrumentsOnce sync.Once
+
+	upstreamDuration     metric.Float64Histogram
+	upstreamErrors       metric.Int64Counter
+	cacheLookups         metric.Int64Counter
+	coalescedCalls       metric.Int64Counter
+	weatherProviderUsage metric.Int64Counter
+	addressProviderUsage metric.Int64Counter
+)
+
+// instruments creates the application instruments on the global meter. The
+// global meter forwards them to the provider installed by Setup, even when
+// they are created before it.
+func instruments() {
+	instrumentsOnce.Do(func() {
+		meter := otel.Meter("Telemetry")
+
+		var (
+			err  error
+			errs []error
+		)
+
+		upstreamDuration, err = meter.Float64Histogram(
+			"upstream.request.duration",
+			metric.WithUnit("s"),
+			metric.WithDescription("Duration of the calls made to the upstream APIs, retries included."),
+		)
+		errs = append(errs, err)
+
+		upstreamErrors, err = meter.Int64Counter(
+			"upstream.request.errors",
+			metric.WithDescription("Calls to the upstream APIs that failed, by kind of error."),
+		)
+		errs = append(errs, err)
+
+		cacheLookups, err = meter.Int64Counter(
+			"cache.lookups",
+			metric.WithDescription("Cache lookups, split between hits and misses by the cache.hit attribute."),
+		)
+		errs = append(errs, err)
+
+		coalescedCalls, err = meter.Int64Counter(
+			"coalesce.calls",
+			metric.WithDescription("Deduplicated lookups; coalesce.shared is true when the result of a call already in flight was reused."),
+		)
+		errs = append(errs, err)
+
+		weatherProviderUsage, err = meter.Int64Counter(
+			"weather.provider.usage",
+			metric.WithDescription("Weather answers by provider; weather.fallback is true when a previous provider of the chain failed."),
+		)
+		errs = append(errs, err)
+
+		addressProviderUsage, err = meter.Int64Counter(
+			"address.provider.usage",
+			metric.WithDescription("Address answers by provider; address.fallback is true when the provider is not the first of the chain."),
+		)
+		errs = append(errs, err)
+
+		if err := errors.Join(errs...); err != nil {
+			otel.Handle(err)
+		}
+	})
+}
+
+// RecordUpstreamCall records the duration of an upstream call started at
+// start and, when the call failed, its kind of error. It is meant to be
+// deferred by functions with a named error result.
+func RecordUpstreamCall(upstream string, start time.Time, err *error, ctx context.Context) {
+	instruments()
+
+	upstreamAttribute := attribute.String("upstream.name", upstream)
+
+	upstreamDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(upstreamAttribute))
+
+	if err != nil && *err != nil {
+		upstreamErrors.Add(ctx, 1, metric.WithAttributes(upstreamAttribute, attribute.String("error.type", errorType(*err))))
+	}
+}
+
+func RecordCacheLookup(cache string, hit bool, ctx context.Context) {
+	instruments()
+
+	cacheLookups.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache.name", cache),
+		attribute.Bool("cache.hit", hit),
+	))
+}
+
+func RecordCoalescedCall(name string, shared bool, ctx context.Context) {
+	instruments()
+
+	coalescedCalls.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("coalesce.name", name),
+		attribute.Bool("coalesce.shared", shared),
+	))
+}
+
+func RecordWeatherProvider(provider string, fallback bool, ctx context.Context) {
+	instruments()
+
+	weatherProviderUsage.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("weather.provider", provider),
+		attribute.Bool("weather.fallback", fallback),
+	))
+}
+
+func RecordAddressProvider(provider string, fallback bool, ctx context.Context) {
+	instruments()
+
+	addressProviderUsage.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("address.provider", provider),
+		attribute.Bool("address.fallback", fallback),
+	))
+}
+
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidCEP):
+		return "invalid_cep"
+	case errors.Is(err, apperrors.ErrCEPNotFound):
+		return "cep_not_found"
+	case errors.Is(err, apperrors.ErrLocationNotFound):
+		return "location_not_found"
+	case errors.Is(err, apperrors.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, apperrors.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, apperrors.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, apperrors.ErrUpstreamBadPayload):
+		return "bad_payload"
+	case errors.Is(err, apperrors.ErrUpstreamUnavailable):
+		return "unavailable"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "other"
+	}
+}
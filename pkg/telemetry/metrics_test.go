@@ -0,0 +1,81 @@
+package telemetry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/apperrors"
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+
+	var timeoutErr error = apperrors.Transport("ViaCEP", context.DeadlineExceeded, "error when searching for zipcode")
+	var noErr error
+
+	telemetry.RecordUpstreamCall("ViaCEP", time.Now(), &timeoutErr, ctx)
+	telemetry.RecordUpstreamCall("ViaCEP", time.Now(), &noErr, ctx)
+	telemetry.RecordCacheLookup("address", true, ctx)
+	telemetry.RecordCacheLookup("address", false, ctx)
+	telemetry.RecordCacheLookup("address", true, ctx)
+	telemetry.RecordWeatherProvider("wttr.in", true, ctx)
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	duration, ok := metrics["upstream.request.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 || duration.DataPoints[0].Count != 2 {
+		t.Errorf("Expected 2 upstream durations, got %+v", metrics["upstream.request.duration"])
+	}
+
+	upstreamErrors, ok := metrics["upstream.request.errors"].(metricdata.Sum[int64])
+	if !ok || len(upstreamErrors.DataPoints) != 1 {
+		t.Fatalf("Expected 1 upstream error point, got %+v", metrics["upstream.request.errors"])
+	}
+
+	if value, _ := upstreamErrors.DataPoints[0].Attributes.Value("error.type"); value.AsString() != "timeout" {
+		t.Errorf("Expected error.type timeout, got %s", value.AsString())
+	}
+
+	cacheLookups, ok := metrics["cache.lookups"].(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("Expected cache lookups, got %+v", metrics["cache.lookups"])
+	}
+
+	hits := map[bool]int64{}
+	for _, point := range cacheLookups.DataPoints {
+		hit, _ := point.Attributes.Value(attribute.Key("cache.hit"))
+		hits[hit.AsBool()] = point.Value
+	}
+
+	if hits[true] != 2 || hits[false] != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %v", hits)
+	}
+
+	usage, ok := metrics["weather.provider.usage"].(metricdata.Sum[int64])
+	if !ok || len(usage.DataPoints) != 1 {
+		t.Fatalf("Expected 1 weather provider point, got %+v", metrics["weather.provider.usage"])
+	}
+
+	if fallback, _ := usage.DataPoints[0].Attributes.Value("weather.fallback"); !fallback.AsBool() {
+		t.Error("Expected weather.fallback to be true")
+	}
+}
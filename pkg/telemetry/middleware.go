@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewHandler instruments the whole router with a server span per request,
+// continuing the trace propagated by the caller.
+func NewHandler(handler http.Handler, service string) http.Handler {
+	return otelhttp.NewHandler(handler, service)
+}
+
+// RouteMiddleware names the server span after the chi route that answered the
+// request and adds the route to the request metrics. The route is only known
+// once chi has routed the request, so both happen after the handler returns.
+func RouteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		routeContext := chi.RouteContext(r.Context())
+		if routeContext == nil {
+			return
+		}
+
+		route := routeContext.RoutePattern()
+		if route == "" {
+			return
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+
+		if labeler, ok := otelhttp.LabelerFromContext(r.Context()); ok {
+			labeler.Add(semconv.HTTPRoute(route))
+		}
+	})
+}
+
+// NewTransport instruments an outbound transport with a client span per
+// request and injects the trace context in the request headers.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// NewPrometheusExporter returns a metric reader to be added to the meter
+// provider and the handler that serves what it reads in the Prometheus text
+// format. Every exporter has its own registry, next to the Go runtime and
+// process collectors.
+func NewPrometheusExporter() (sdkmetric.Reader, http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+
+	return exporter, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
@@ -0,0 +1,51 @@
+package telemetry_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestNewPrometheusExporter(t *testing.T) {
+	reader, handler, err := telemetry.NewPrometheusExporter()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer meterProvider.Shutdown(context.Background())
+
+	counter, err := meterProvider.Meter("test").Int64Counter("upstream.request.errors")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	counter.Add(context.Background(), 3, metric.WithAttributes(attribute.String("upstream.name", "ViaCEP")))
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if !strings.Contains(string(body), `upstream_request_errors_total{otel_scope_name="test",otel_scope_version="",upstream_name="ViaCEP"} 3`) {
+		t.Errorf("Expected the upstream errors counter, got %s", body)
+	}
+
+	if !strings.Contains(string(body), "go_goroutines") {
+		t.Error("Expected the Go runtime metrics")
+	}
+}
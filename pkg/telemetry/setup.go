@@ -0,0 +1,133 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+type Settings struct {
+	ServiceName string
+	// OTLP enables pushing traces, metrics and logs to the collector. Without
+	// it the spans are still created, so the trace context keeps being
+	// propagated.
+	OTLP bool
+	// CollectorURL is the host:port of the OTLP gRPC receiver of the collector.
+	CollectorURL string
+	// MetricsInterval is how often the metrics are pushed to the collector.
+	MetricsInterval time.Duration
+	// Prometheus enables serving the metrics at /metrics on PrometheusPort.
+	Prometheus     bool
+	PrometheusPort int
+}
+
+// Setup installs the global tracer, meter and logger providers, exporting to
+// the collector through OTLP gRPC and serving the metrics to Prometheus, as
+// enabled by the settings. The returned function flushes and stops them.
+func Setup(settings Settings, ctx context.Context) (func(context.Context) error, error) {
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(settings.ServiceName),
+	)
+
+	tracerOptions := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	meterOptions := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	loggerOptions := []sdklog.LoggerProviderOption{sdklog.WithResource(res)}
+
+	if settings.OTLP {
+		traceExporter, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithInsecure(),
+			otlptracegrpc.WithEndpoint(settings.CollectorURL),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC trace exporter: %w", err)
+		}
+
+		metricExporter, err := otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithInsecure(),
+			otlpmetricgrpc.WithEndpoint(settings.CollectorURL),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC metric exporter: %w", err)
+		}
+
+		logExporter, err := otlploggrpc.New(ctx,
+			otlploggrpc.WithInsecure(),
+			otlploggrpc.WithEndpoint(settings.CollectorURL),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC log exporter: %w", err)
+		}
+
+		var readerOptions []sdkmetric.PeriodicReaderOption
+		if settings.MetricsInterval > 0 {
+			readerOptions = append(readerOptions, sdkmetric.WithInterval(settings.MetricsInterval))
+		}
+
+		tracerOptions = append(tracerOptions, sdktrace.WithBatcher(traceExporter))
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, readerOptions...)))
+		loggerOptions = append(loggerOptions, sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)))
+	}
+
+	var metricsServer *http.Server
+
+	if settings.Prometheus {
+		reader, handler, err := NewPrometheusExporter()
+		if err != nil {
+			return nil, err
+		}
+
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", settings.PrometheusPort))
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen for Prometheus metrics: %w", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", handler)
+
+		metricsServer = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go metricsServer.Serve(listener)
+
+		meterOptions = append(meterOptions, sdkmetric.WithReader(reader))
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(tracerOptions...)
+	meterProvider := sdkmetric.NewMeterProvider(meterOptions...)
+	loggerProvider := sdklog.NewLoggerProvider(loggerOptions...)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	global.SetLoggerProvider(loggerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	shutdown := func(ctx context.Context) error {
+		errs := []error{
+			tracerProvider.Shutdown(ctx),
+			meterProvider.Shutdown(ctx),
+			loggerProvider.Shutdown(ctx),
+		}
+
+		if metricsServer != nil {
+			errs = append(errs, metricsServer.Shutdown(ctx))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	return shutdown, nil
+}
@@ -0,0 +1,27 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RecordError records err as an exception event of the span and marks the
+// span as failed. A nil error leaves the span untouched.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// EndSpan records the error pointed by err, if any, and ends the span. It is
+// meant to be deferred by functions with a named error result.
+func EndSpan(span trace.Span, err *error) {
+	if err != nil {
+		RecordError(span, *err)
+	}
+
+	span.End()
+}
@@ -0,0 +1,147 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/telemetry"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTracerProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	tracerProvider, recorder := newTracerProvider()
+
+	router := chi.NewRouter()
+	router.Use(telemetry.RouteMiddleware)
+	router.Get("/weather/{cep}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	handler := otelhttp.NewHandler(router, "Service B", otelhttp.WithTracerProvider(tracerProvider))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/weather/01001000", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	if spans[0].Name() != "GET /weather/{cep}" {
+		t.Errorf("Expected span name GET /weather/{cep}, got %s", spans[0].Name())
+	}
+
+	attributes := map[string]string{}
+	for _, attribute := range spans[0].Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+
+	if attributes["http.route"] != "/weather/{cep}" {
+		t.Errorf("Expected http.route /weather/{cep}, got %q", attributes["http.route"])
+	}
+
+	if attributes["http.status_code"] != "404" {
+		t.Errorf("Expected http.status_code 404, got %q", attributes["http.status_code"])
+	}
+}
+
+func TestNewTransportInjectsTraceContext(t *testing.T) {
+	tracerProvider, _ := newTracerProvider()
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	transport := telemetry.NewTransport(http.DefaultTransport)
+
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if traceparent == "" {
+		t.Fatal("Expected the traceparent header to be sent")
+	}
+
+	if traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("Expected trace id %s in %s", span.SpanContext().TraceID(), traceparent)
+	}
+}
+
+func TestEndSpan(t *testing.T) {
+	tracerProvider, recorder := newTracerProvider()
+	tracer := tracerProvider.Tracer("test")
+
+	err := errors.New("upstream failure")
+	_, span := tracer.Start(context.Background(), "failure")
+	telemetry.EndSpan(span, &err)
+
+	var noErr error
+	_, span = tracer.Start(context.Background(), "success")
+	telemetry.EndSpan(span, &noErr)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	if spans[0].Status().Code != codes.Error || spans[0].Status().Description != "upstream failure" {
+		t.Errorf("Expected error status, got %+v", spans[0].Status())
+	}
+
+	if len(spans[0].Events()) != 1 {
+		t.Errorf("Expected 1 exception event, got %d", len(spans[0].Events()))
+	}
+
+	if spans[1].Status().Code != codes.Unset || len(spans[1].Events()) != 0 {
+		t.Errorf("Expected untouched span, got %+v", spans[1].Status())
+	}
+}
+
+func TestCEPAttribute(t *testing.T) {
+	attribute := telemetry.CEPAttribute("01001000")
+
+	if attribute.Key != telemetry.CEPHashKey {
+		t.Errorf("Expected key %s, got %s", telemetry.CEPHashKey, attribute.Key)
+	}
+
+	if value := attribute.Value.AsString(); len(value) != 16 || value == "01001000" {
+		t.Errorf("Expected a 16 characters hash, got %s", value)
+	}
+
+	for _, value := range []string{"01001000", "01001-000", " 01.001-000 "} {
+		if telemetry.CEPAttribute(value) != attribute {
+			t.Errorf("Expected the same hash for %q", value)
+		}
+	}
+
+	if telemetry.CEPAttribute("01001") == attribute {
+		t.Error("Expected another hash for another value")
+	}
+}
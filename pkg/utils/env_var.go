@@ -1,10 +0,0 @@
-package utils
-
-import "os"
-
-func GetEnvOrDefault(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
-}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BuildURL appends the path segments and the query to baseURL, escaping each
+// segment on its own so values like a city name can never change the path
+// structure. An empty last segment keeps a trailing slash.
+func BuildURL(baseURL string, segments []string, query url.Values) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %q: %w", baseURL, err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid base url %q: scheme and host are required", baseURL)
+	}
+
+	escapedPath := strings.TrimSuffix(u.EscapedPath(), "/")
+	for _, segment := range segments {
+		escapedPath += "/" + url.PathEscape(segment)
+	}
+
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+
+	u.Path, err = url.PathUnescape(escapedPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", escapedPath, err)
+	}
+
+	u.RawPath = escapedPath
+
+	if len(query) > 0 {
+		values := u.Query()
+		for key, value := range query {
+			values[key] = value
+		}
+
+		u.RawQuery = values.Encode()
+	}
+
+	return u.String(), nil
+}
@@ -0,0 +1,44 @@
+package utils_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aronkst/go-telemetry-cep-temperature/pkg/utils"
+)
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		segments []string
+		query    url.Values
+		want     string
+	}{
+		{"https://viacep.com.br", []string{"ws", "01001000", "json", ""}, nil, "https://viacep.com.br/ws/01001000/json/"},
+		{"https://viacep.com.br/", []string{"ws", "01001000", "json", ""}, nil, "https://viacep.com.br/ws/01001000/json/"},
+		{"http://localhost:8080", nil, url.Values{"cep": {"01001000"}}, "http://localhost:8080/?cep=01001000"},
+		{"https://wttr.in", []string{"São Paulo,SP,Brazil"}, url.Values{"format": {"j1"}}, "https://wttr.in/S%C3%A3o%20Paulo%2CSP%2CBrazil?format=j1"},
+		{"https://example.com/api", []string{"a/b"}, nil, "https://example.com/api/a%2Fb"},
+		{"https://example.com?key=1", []string{"search"}, url.Values{"q": {"a&b"}}, "https://example.com/search?key=1&q=a%26b"},
+	}
+
+	for _, test := range tests {
+		got, err := utils.BuildURL(test.baseURL, test.segments, test.query)
+		if err != nil {
+			t.Errorf("BuildURL(%q, %q, %v) returned error %v", test.baseURL, test.segments, test.query, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("BuildURL(%q, %q, %v) = %q; want %q", test.baseURL, test.segments, test.query, got, test.want)
+		}
+	}
+}
+
+func TestBuildURL_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "viacep.com.br", "://bad"} {
+		if _, err := utils.BuildURL(baseURL, nil, nil); err == nil {
+			t.Errorf("BuildURL(%q) expected an error", baseURL)
+		}
+	}
+}